@@ -30,89 +30,70 @@ func init() {
 	}
 }
 
-func UseOn(ctx context.Context, eventName string, handle func(event Event)) *Ref {
-	elRef := UseRef(ctx, nil)
-	handleRef := UseRef(ctx, nil)
-	handleRef.Current = handle
-
-	UseEffect(ctx, func() func() {
-		if n, ok := elRef.Current.(Node); ok {
-			h := func(event Event) {
-				handleRef.Current.(func(event Event))(event)
-			}
-
-			n.AddEventListener(eventName, h)
-			return func() {
-				n.RemoveEventListener(eventName, h)
-			}
-		}
-		return nil
-	}, []interface{}{elRef.Current})
-
-	return elRef
-}
-
 type SubComp struct {
 }
 
 func (a SubComp) Render(ctx context.Context, children ...interface{}) interface{} {
-	refInput := UseOn(ctx, "click", func(event Event) {
-		fmt.Println("click")
-	})
-	return Button(refInput, "button")
+	return Button(
+		Attr("onClick", func(event Event) {
+			fmt.Println("click")
+		}),
+		"button",
+	)
 }
 
-type App struct {
+type DogBreeds struct {
 }
 
-func (a App) Render(ctx context.Context, children ...interface{}) interface{} {
-	s := time.Now()
-	defer func() {
-		fmt.Println("render app cost", time.Since(s))
-	}()
+func (DogBreeds) Render(ctx context.Context, children ...interface{}) interface{} {
+	breeds, err := UseResource(ctx, "dog-breeds", func(ctx context.Context) ([]string, error) {
+		c := httputil.GetShortConnClientContext(ctx, 5*time.Second)
+		req, _ := http.NewRequestWithContext(ctx, "GET", "https://dog.ceo/api/breeds/list/all", nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
 
-	UseEffect(ctx, func() func() {
-		fmt.Println("render app full cost", time.Since(s))
-		return nil
-	}, nil)
+		m := struct {
+			Message map[string]interface{} `json:"message"`
+		}{}
 
-	value, setValue := UseState(ctx, "")
-	v, setValue2 := UseState(ctx, &[]string{})
-	emojis := *v.(*[]string)
+		if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+			return nil, err
+		}
 
-	hello := UseMemo(ctx, func() interface{} {
-		return "Hello"
-	}, []interface{}{}).(string)
+		keys := make([]string, 0, len(m.Message))
+
+		for i := range m.Message {
+			keys = append(keys, i)
+		}
 
-	UseEffect(ctx, func() func() {
-		go func() {
-			c := httputil.GetShortConnClientContext(context.Background(), 5*time.Second)
-			req, _ := http.NewRequest("GET", "https://dog.ceo/api/breeds/list/all", nil)
-			resp, _ := c.Do(req)
-			m := struct {
-				Message map[string]interface{} `json:"message"`
-			}{}
+		return keys, nil
+	})
 
-			_ = json.NewDecoder(resp.Body).Decode(&m)
+	if err != nil {
+		return Div(err.Error())
+	}
 
-			keys := make([]string, 0, len(m.Message))
+	return RangeSlice(breeds, func(i int) interface{} {
+		return Div(
+			Key(breeds[i]),
+			breeds[i],
+		)
+	})
+}
 
-			for i := range m.Message {
-				keys = append(keys, i)
-			}
+type App struct {
+}
 
-			setValue2(&keys)
-		}()
+func (a App) Render(ctx context.Context, children ...interface{}) interface{} {
+	value, setValue := UseState(ctx, "")
 
-		return nil
+	hello := UseMemo(ctx, func() string {
+		return "Hello"
 	}, []interface{}{})
 
-	refInput := UseOn(ctx, "input", func(event Event) {
-		setValue(func(v interface{}) interface{} {
-			return event.Target().(Element).Get("value")
-		})
-	})
-
 	return Provider(withCSSCache(css))(
 		Main(
 			CSS{
@@ -130,8 +111,15 @@ func (a App) Render(ctx context.Context, children ...interface{}) interface{} {
 			),
 			Div(
 				Input(
-					refInput,
-					Attr("value", value),
+					Attrs{
+						"value": value,
+						"onInput": func(event Event) {
+							setValue(func(prev string) string {
+								v, _ := event.Target().(Element).Get("value").(string)
+								return v
+							})
+						},
+					},
 				),
 			),
 			H(SubComp{})(),
@@ -140,12 +128,9 @@ func (a App) Render(ctx context.Context, children ...interface{}) interface{} {
 					"overflow": "scroll",
 					"height":   "2em",
 				},
-				RangeSlice(emojis, func(i int) interface{} {
-					return Div(
-						Key(emojis[i]),
-						emojis[i],
-					)
-				}),
+				Suspense(Div("loading..."))(
+					H(DogBreeds{})(),
+				),
 			),
 			Div(
 				CSS{
@@ -169,7 +154,11 @@ func (a App) Render(ctx context.Context, children ...interface{}) interface{} {
 
 func main() {
 	r := renderer.CreateRoot(Document.QuerySelector("#root"))
-	_ = r.Render(context.Background(), H(App{})())
+	_ = r.Render(context.Background(), Profiler("app", func(info ProfileInfo) {
+		fmt.Println("render app", info.Phase, "cost", info.ActualDuration)
+	})(
+		H(App{})(),
+	))
 	fmt.Println("App ready hello")
 	<-make(chan struct{})
 }
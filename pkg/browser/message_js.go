@@ -0,0 +1,61 @@
+//go:build js && wasm
+// +build js,wasm
+
+package browser
+
+import "syscall/js"
+
+// OnMessage listens string messages posted to the window from origins,
+// only messages from the same origin accepted when origins empty.
+// reply posts data back to the source of the message.
+func OnMessage(origins []string, fn func(data string, reply func(data string))) (stop func()) {
+	window := js.Global()
+
+	allowed := func(origin string) bool {
+		if len(origins) == 0 {
+			return origin == window.Get("location").Get("origin").String()
+		}
+		for _, o := range origins {
+			if o == "*" || o == origin {
+				return true
+			}
+		}
+		return false
+	}
+
+	listener := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		e := args[0]
+
+		origin := e.Get("origin").String()
+		if !allowed(origin) {
+			return nil
+		}
+
+		data := e.Get("data")
+		if data.Type() != js.TypeString {
+			return nil
+		}
+
+		source := e.Get("source")
+		if source.IsNull() || source.IsUndefined() {
+			source = window
+		}
+
+		targetOrigin := origin
+		if targetOrigin == "null" {
+			targetOrigin = "*"
+		}
+
+		fn(data.String(), func(data string) {
+			source.Call("postMessage", data, targetOrigin)
+		})
+		return nil
+	})
+
+	window.Call("addEventListener", "message", listener)
+
+	return func() {
+		window.Call("removeEventListener", "message", listener)
+		listener.Release()
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !js
+// +build !js
+
+package browser
+
+// just fake here in go, no window to post messages
+func OnMessage(origins []string, fn func(data string, reply func(data string))) (stop func()) {
+	return func() {}
+}
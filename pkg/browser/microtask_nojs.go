@@ -0,0 +1,9 @@
+//go:build !js
+// +build !js
+
+package browser
+
+// just fake here in go
+func QueueMicrotask(fn func()) {
+	fn()
+}
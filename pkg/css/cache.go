@@ -86,6 +86,7 @@ func (c *CSSCache) Mount(ctx context.Context, ss *SerializedStyles) {
 	c.mount.AppendChild(d)
 }
 
+// toStylesBytes serializes properties like dom.StyleString, and nested rules by their selectors
 func toStylesBytes(s map[string]interface{}) []byte {
 	if len(s) == 0 {
 		return nil
@@ -102,40 +103,21 @@ func toStylesBytes(s map[string]interface{}) []byte {
 	b := bytes.NewBuffer(nil)
 
 	for _, k := range keys {
-		v := s[k]
-		_, _ = fmt.Fprintf(b, "%s", toSnake(k))
-
-		switch x := v.(type) {
-		case CSS:
+		if rules, ok := dom.StyleMapOf(s[k]); ok {
+			b.WriteString(k)
 			b.WriteByte('{')
-			b.Write(toStylesBytes(x))
-			b.WriteByte('}')
-		case fmt.Stringer:
-			_, _ = fmt.Fprintf(b, ":%s", x.String())
-		default:
-			_, _ = fmt.Fprintf(b, ":%v", v)
+			b.Write(toStylesBytes(rules))
+			b.WriteString("};")
+			continue
 		}
 
-		b.WriteByte(';')
-	}
-
-	return b.Bytes()
-}
-
-func toSnake(id string) string {
-	buf := bytes.NewBuffer(nil)
-
-	chars := []byte(id)
-
-	for _, c := range chars {
-		if c >= 'A' && c <= 'Z' {
-			buf.WriteByte('-')
-			c += 'a' - 'A'
+		property := dom.CSSPropertyName(k)
+		if value, ok := dom.CSSValue(property, s[k]); ok {
+			_, _ = fmt.Fprintf(b, "%s:%s;", property, value)
 		}
-		buf.WriteByte(c)
 	}
 
-	return buf.String()
+	return b.Bytes()
 }
 
 type contextKeyCSSCache struct{}
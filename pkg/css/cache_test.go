@@ -6,6 +6,8 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/go-courier/gox/pkg/dom"
+	. "github.com/onsi/gomega"
 )
 
 func TestCache(t *testing.T) {
@@ -26,3 +28,17 @@ func TestCache(t *testing.T) {
 
 	spew.Dump(s)
 }
+
+func TestStyles(t *testing.T) {
+	styles := CSS{
+		"fontSize":    12,
+		"--mainColor": "red",
+		"opacity":     0.5,
+		"&:hover": CSS{
+			"marginTop": 4,
+		},
+	}
+
+	NewWithT(t).Expect(styles.Styles()).To(Equal(`&:hover{margin-top:4px;};--mainColor:red;font-size:12px;opacity:0.5;`))
+	NewWithT(t).Expect(CSS{"fontSize": 12, "opacity": 0.5}.Styles()).To(Equal(dom.StyleString(map[string]interface{}{"font-size": 12, "opacity": 0.5})))
+}
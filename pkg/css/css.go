@@ -27,8 +27,9 @@ func (s CSS) Attrs(ctx context.Context) gox.Attrs {
 		}
 	}
 
+	// as map, so that only changed properties will be updated
 	return gox.Attrs{
-		"style": s.Styles(),
+		"style": s,
 	}
 }
 
@@ -3,7 +3,17 @@ package dom
 type Doc interface {
 	JSObject
 
+	DocumentElement() Element
+	Head() Element
+	Body() Element
+
 	CreateElement(tagName string) Element
-	CreateTextNode(tagName string) Element
-	QuerySelector(id string) Element
+	CreateTextNode(data string) Element
+	CreateComment(data string) Element
+	// CreateDocumentFragment creates the fragment, of which children are moved when inserted
+	CreateDocumentFragment() Element
+	// CreateDocumentType creates the doctype like <!DOCTYPE html>
+	CreateDocumentType(name string) Element
+	QuerySelector(selectors string) Element
+	QuerySelectorAll(selectors string) ElementList
 }
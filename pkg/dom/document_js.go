@@ -25,14 +25,24 @@ func (e *jsDocument) Set(propName string, v interface{}) {
 	e.JSValue.Set(propName, v)
 }
 
+func (d *jsDocument) DocumentElement() Element {
+	return asElement(d.JSValue.Get("documentElement"))
+}
+
+func (d *jsDocument) Head() Element {
+	return asElement(d.JSValue.Get("head"))
+}
+
+func (d *jsDocument) Body() Element {
+	return asElement(d.JSValue.Get("body"))
+}
+
 func (d *jsDocument) QuerySelector(s string) Element {
-	jsVal := d.Call("querySelector", s)
-	if jsVal.IsNull() {
-		return nil
-	}
-	return &jsElement{
-		JSValue: jsVal,
-	}
+	return asElement(d.Call("querySelector", s))
+}
+
+func (d *jsDocument) QuerySelectorAll(s string) ElementList {
+	return asElementList(d.Call("querySelectorAll", s))
 }
 
 func (d *jsDocument) CreateElement(tagName string) Element {
@@ -42,3 +52,24 @@ func (d *jsDocument) CreateElement(tagName string) Element {
 func (d *jsDocument) CreateTextNode(text string) Element {
 	return &jsElement{JSValue: d.Call("createTextNode", text)}
 }
+
+// createDocumentNode creates an empty html document
+func createDocumentNode() Element {
+	doc := js.Global().Get("document").Get("implementation").Call("createHTMLDocument", "")
+	for c := doc.Get("firstChild"); !c.IsNull(); c = doc.Get("firstChild") {
+		doc.Call("removeChild", c)
+	}
+	return asElement(doc)
+}
+
+func (d *jsDocument) CreateComment(data string) Element {
+	return &jsElement{JSValue: d.Call("createComment", data)}
+}
+
+func (d *jsDocument) CreateDocumentFragment() Element {
+	return &jsElement{JSValue: d.Call("createDocumentFragment")}
+}
+
+func (d *jsDocument) CreateDocumentType(name string) Element {
+	return &jsElement{JSValue: d.JSValue.Get("implementation").Call("createDocumentType", name, "", "")}
+}
@@ -4,22 +4,46 @@
 package dom
 
 var (
-	Document = &document{
-		root: &element{
-			nodeType: DOCUMENT_NODE,
-		},
-	}
+	Document = newDocument()
 )
 
+func newDocument() *document {
+	d := &document{
+		root: createDocumentNode().(*element),
+	}
+
+	html := d.CreateElement("html")
+	html.AppendChild(d.CreateElement("head"))
+	html.AppendChild(d.CreateElement("body"))
+	d.root.AppendChild(html)
+
+	return d
+}
+
 type document struct {
-	root Element
+	root *element
 
 	object
 }
 
-func (document) QuerySelector(selector string) Element {
-	// todo
-	return nil
+func (d *document) DocumentElement() Element {
+	return d.root.QuerySelector("html")
+}
+
+func (d *document) Head() Element {
+	return d.root.QuerySelector("html > head")
+}
+
+func (d *document) Body() Element {
+	return d.root.QuerySelector("html > body")
+}
+
+func (d *document) QuerySelector(selectors string) Element {
+	return d.root.QuerySelector(selectors)
+}
+
+func (d *document) QuerySelectorAll(selectors string) ElementList {
+	return d.root.QuerySelectorAll(selectors)
 }
 
 func (document) CreateElement(tagName string) Element {
@@ -35,3 +59,32 @@ func (document) CreateTextNode(data string) Element {
 		textContent: data,
 	}
 }
+
+func createDocumentNode() Element {
+	return &element{
+		nodeType: DOCUMENT_NODE,
+		tagName:  "#document",
+	}
+}
+
+func (document) CreateComment(data string) Element {
+	return &element{
+		nodeType:    COMMENT_NODE,
+		tagName:     "#comment",
+		textContent: data,
+	}
+}
+
+func (document) CreateDocumentFragment() Element {
+	return &element{
+		nodeType: DOCUMENT_FRAGMENT_NODE,
+		tagName:  "#document-fragment",
+	}
+}
+
+func (document) CreateDocumentType(name string) Element {
+	return &element{
+		nodeType: DOCUMENT_TYPE_NODE,
+		tagName:  name,
+	}
+}
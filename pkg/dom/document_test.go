@@ -49,3 +49,92 @@ func TestNode(t *testing.T) {
 		}
 	})
 }
+
+func TestDocumentFragment(t *testing.T) {
+	html := func(n Node) string {
+		buf := bytes.NewBuffer(nil)
+		RenderToHTML(buf, n)
+		return buf.String()
+	}
+
+	t.Run("should move all children when inserted", func(t *testing.T) {
+		p := Document.CreateElement("ul")
+		last := Document.CreateElement("li")
+		p.AppendChild(last)
+
+		f := Document.CreateDocumentFragment()
+		f.AppendChild(Document.CreateElement("b"))
+		f.AppendChild(Document.CreateComment("c"))
+		NewWithT(t).Expect(html(f)).To(Equal("<b></b><!--c-->"))
+
+		p.InsertBefore(f, last)
+		NewWithT(t).Expect(html(p)).To(Equal("<ul><b></b><!--c--><li></li></ul>"))
+		NewWithT(t).Expect(f.FirstChild()).To(BeNil())
+		NewWithT(t).Expect(p.FirstChild().NextSibling().NodeName()).To(Equal("#comment"))
+
+		f.AppendChild(Document.CreateTextNode("t"))
+		p.AppendChild(f)
+		NewWithT(t).Expect(html(p)).To(Equal("<ul><b></b><!--c--><li></li>t</ul>"))
+		NewWithT(t).Expect(p.LastChild().ParentNode()).To(Equal(p))
+	})
+
+	t.Run("should render doctype", func(t *testing.T) {
+		f := Document.CreateDocumentFragment()
+		f.AppendChild(Document.CreateDocumentType("html"))
+		f.AppendChild(Document.CreateElement("html"))
+
+		NewWithT(t).Expect(f.FirstChild().NodeType()).To(Equal(DOCUMENT_TYPE_NODE))
+		NewWithT(t).Expect(html(f)).To(Equal("<!DOCTYPE html><html></html>"))
+	})
+}
+
+func TestElementProperties(t *testing.T) {
+	html := func(n Node) string {
+		buf := bytes.NewBuffer(nil)
+		RenderToHTML(buf, n)
+		return buf.String()
+	}
+
+	t.Run("should reflect className to class", func(t *testing.T) {
+		p := Document.CreateElement("div")
+		p.Set("className", "a b")
+
+		NewWithT(t).Expect(html(p)).To(Equal(`<div class="a b"></div>`))
+		NewWithT(t).Expect(p.Get("className")).To(Equal("a b"))
+
+		p.SetAttribute("class", "c")
+		NewWithT(t).Expect(p.Get("className")).To(Equal("c"))
+	})
+
+	t.Run("should replace children by innerHTML", func(t *testing.T) {
+		p := Document.CreateElement("ul")
+		p.AppendChild(Document.CreateElement("li"))
+		p.Set("innerHTML", `<li class="a">1</li><!--c-->`)
+
+		NewWithT(t).Expect(html(p)).To(Equal(`<ul><li class="a">1</li><!--c--></ul>`))
+		NewWithT(t).Expect(p.Get("innerHTML")).To(Equal(`<li class="a">1</li><!--c-->`))
+		NewWithT(t).Expect(html(p.QuerySelector(".a"))).To(Equal(`<li class="a">1</li>`))
+	})
+
+	t.Run("should select option by value of select", func(t *testing.T) {
+		p := Document.CreateElement("select")
+		p.Set("innerHTML", `<option value="a">A</option><optgroup><option>b</option></optgroup>`)
+
+		NewWithT(t).Expect(p.Get("value")).To(Equal("a"))
+
+		p.Set("value", "b")
+		NewWithT(t).Expect(p.Get("value")).To(Equal("b"))
+		NewWithT(t).Expect(p.FirstChild().(Element).Get("selected")).To(Equal(false))
+
+		p.Set("value", "c")
+		NewWithT(t).Expect(p.Get("value")).To(Equal("a"))
+	})
+
+	t.Run("should keep other properties", func(t *testing.T) {
+		p := Document.CreateElement("input")
+		p.Set("value", "1")
+
+		NewWithT(t).Expect(html(p)).To(Equal(`<input>`))
+		NewWithT(t).Expect(p.Get("value")).To(Equal("1"))
+	})
+}
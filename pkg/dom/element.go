@@ -8,6 +8,13 @@ type Element interface {
 	SetAttribute(k string, v interface{})
 	GetAttribute(k string) interface{}
 	RemoveAttribute(k string)
+
+	Style() CSSStyleDeclaration
+
+	QuerySelector(selectors string) Element
+	QuerySelectorAll(selectors string) ElementList
+	Matches(selectors string) bool
+	Closest(selectors string) Element
 }
 
 type ElementList []Element
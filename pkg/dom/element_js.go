@@ -4,7 +4,6 @@
 package dom
 
 import (
-	"reflect"
 	"strings"
 	"sync"
 	"syscall/js"
@@ -14,7 +13,6 @@ import (
 
 type jsElement struct {
 	JSValue
-	listeners sync.Map
 }
 
 func (e *jsElement) Get(propName string) interface{} {
@@ -129,35 +127,157 @@ func (e *jsElement) GetAttributeNames() []string {
 	return names
 }
 
+func (e *jsElement) QuerySelector(selectors string) Element {
+	return asElement(e.Call("querySelector", selectors))
+}
+
+func (e *jsElement) QuerySelectorAll(selectors string) ElementList {
+	return asElementList(e.Call("querySelectorAll", selectors))
+}
+
+func (e *jsElement) Matches(selectors string) bool {
+	return e.Call("matches", selectors).Bool()
+}
+
+func (e *jsElement) Closest(selectors string) Element {
+	return asElement(e.Call("closest", selectors))
+}
+
+func asElementList(v js.Value) ElementList {
+	list := make(ElementList, v.Length())
+	for i := range list {
+		list[i] = asElement(v.Index(i))
+	}
+	return list
+}
+
+// jsListenerKey identifies the listener like the browser does, by event type, capture and the listener
+type jsListenerKey struct {
+	eventName string
+	capture   bool
+	listener  uintptr
+}
+
+func jsListenerKeyOf(eventName string, handle func(Event), args ...interface{}) jsListenerKey {
+	return jsListenerKey{
+		eventName: eventName,
+		capture:   eventListenerOptionsFromArgs(args...).Capture,
+		listener:  listenerKey(handle),
+	}
+}
+
+// propListenersID is the property of js node to find its listeners,
+// wrappers of the same node are created by each asElement, so listeners could not be held by them.
+const propListenersID = "__goxListenersID"
+
+// jsListeners holds js.Func of listeners by the id of js node
+var jsListeners = struct {
+	mu     sync.Mutex
+	seq    int
+	byNode map[int]map[jsListenerKey]js.Func
+}{
+	byNode: map[int]map[jsListenerKey]js.Func{},
+}
+
+// jsListenersIDOf returns the id of v to find its listeners, 0 when not assigned and not create.
+// should be called with jsListeners.mu held.
+func jsListenersIDOf(v js.Value, create bool) int {
+	if id := v.Get(propListenersID); id.Type() == js.TypeNumber {
+		return id.Int()
+	}
+	if !create {
+		return 0
+	}
+	jsListeners.seq++
+	v.Set(propListenersID, jsListeners.seq)
+	return jsListeners.seq
+}
+
+// releaseJSListener deletes and releases the js.Func of k on the node of id
+// should be called with jsListeners.mu held.
+func releaseJSListener(id int, k jsListenerKey) {
+	listeners := jsListeners.byNode[id]
+	fn, ok := listeners[k]
+	if !ok {
+		return
+	}
+	delete(listeners, k)
+	if len(listeners) == 0 {
+		delete(jsListeners.byNode, id)
+	}
+	fn.Release()
+}
+
 func (e *jsElement) AddEventListener(eventName string, handle func(Event), args ...interface{}) {
+	jsListeners.mu.Lock()
+	defer jsListeners.mu.Unlock()
+
+	id := jsListenersIDOf(e.JSValue, true)
+	k := jsListenerKeyOf(eventName, handle, args...)
+
+	if _, ok := jsListeners.byNode[id][k]; ok {
+		// same as browser, adding the same listener again does nothing
+		return
+	}
+
+	once := eventListenerOptionsFromArgs(args...).Once
+
 	fn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if once {
+			// removed by the browser once called
+			jsListeners.mu.Lock()
+			releaseJSListener(id, k)
+			jsListeners.mu.Unlock()
+		}
 		handle(&jsEvent{JSValue: args[0]})
 		return nil
 	})
 
 	if len(args) == 1 {
-		e.Call("addEventListener", eventName, fn, args[0])
+		e.Call("addEventListener", eventName, fn, toJSListenerOptions(args[0]))
 	} else {
 		e.Call("addEventListener", eventName, fn)
 	}
-	e.listeners.Store(reflect.TypeOf(handle), fn)
+
+	if jsListeners.byNode[id] == nil {
+		jsListeners.byNode[id] = map[jsListenerKey]js.Func{}
+	}
+	jsListeners.byNode[id][k] = fn
 }
 
 func (e *jsElement) RemoveEventListener(eventName string, handle func(Event), args ...interface{}) {
-	k := reflect.TypeOf(handle)
-	if fn, ok := e.listeners.Load(k); ok {
-		f := fn.(js.Func)
+	jsListeners.mu.Lock()
+	defer jsListeners.mu.Unlock()
+
+	id := jsListenersIDOf(e.JSValue, false)
+	if id == 0 {
+		return
+	}
+
+	k := jsListenerKeyOf(eventName, handle, args...)
+
+	if fn, ok := jsListeners.byNode[id][k]; ok {
 		if len(args) == 1 {
-			e.Call("removeEventListener", eventName, f, args[0])
+			e.Call("removeEventListener", eventName, fn, toJSListenerOptions(args[0]))
 		} else {
-			e.Call("removeEventListener", eventName, f)
+			e.Call("removeEventListener", eventName, fn)
 		}
-		e.listeners.Delete(k)
-		f.Release()
+		releaseJSListener(id, k)
 	}
 }
 
+func toJSListenerOptions(v interface{}) interface{} {
+	switch x := v.(type) {
+	case EventListenerOptions:
+		return x.Map()
+	case *EventListenerOptions:
+		return x.Map()
+	}
+	return v
+}
+
 func (e *jsElement) DispatchEvent(event Event) {
-	// TODO
-	e.Call("dispatchEvent", event)
+	if je, ok := event.(*jsEvent); ok {
+		e.Call("dispatchEvent", je.JSValue)
+	}
 }
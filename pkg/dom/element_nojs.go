@@ -4,6 +4,8 @@
 package dom
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -14,6 +16,7 @@ type element struct {
 	nodeType    NodeType
 	textContent string
 	attributes  map[string]interface{}
+	listeners   []*eventListener
 
 	object
 
@@ -117,13 +120,30 @@ func (e *element) InsertBefore(newNode, ref Node) Node {
 		return nil
 	}
 
+	if newCNode == refNode {
+		return ref
+	}
+
+	if f, ok := newCNode.(*element); ok && f.NodeType() == DOCUMENT_FRAGMENT_NODE {
+		// children of fragment are moved, and fragment left empty
+		for _, c := range f.takeChildren() {
+			e.InsertBefore(c, ref)
+		}
+		return newNode
+	}
+
+	if newChild, ok := newCNode.(*element); ok {
+		// attached node will be moved
+		newChild.detach()
+	}
+
 	e.rw.Lock()
 	defer e.rw.Unlock()
 
 	if newChild, ok := newCNode.(*element); ok {
 		if oldChild, ok := refNode.(*element); ok {
-			if newChild.parent != nil || newChild.prevSibling != nil || newChild.nextSibling != nil {
-				panic("dom: insertBefore called for an attached child Element")
+			if oldChild.parent != e {
+				panic("dom: insertBefore called for a non-child reference Element")
 			}
 			var prev, next *element
 			if oldChild != nil {
@@ -153,9 +173,6 @@ func (e *element) InsertBefore(newNode, ref Node) Node {
 }
 
 func (e *element) AppendChild(n Node) {
-	e.rw.Lock()
-	defer e.rw.Unlock()
-
 	p := UnWrap(n)
 	if p == nil {
 		return
@@ -165,9 +182,20 @@ func (e *element) AppendChild(n Node) {
 		panic("dom: AppendChild append nil child")
 	}
 
-	if c.parent != nil || c.prevSibling != nil || c.nextSibling != nil {
-		panic("dom: AppendChild called for an attached child Element")
+	if c.NodeType() == DOCUMENT_FRAGMENT_NODE {
+		// children of fragment are moved, and fragment left empty
+		for _, child := range c.takeChildren() {
+			e.AppendChild(child)
+		}
+		return
 	}
+
+	// attached node will be moved
+	c.detach()
+
+	e.rw.Lock()
+	defer e.rw.Unlock()
+
 	last := e.lastChild
 	if last != nil {
 		last.nextSibling = c
@@ -179,6 +207,34 @@ func (e *element) AppendChild(n Node) {
 	c.prevSibling = last
 }
 
+// takeChildren removes all children of e, and returns them in order
+func (e *element) takeChildren() []*element {
+	e.rw.Lock()
+	defer e.rw.Unlock()
+
+	children := make([]*element, 0)
+	for c := e.firstChild; c != nil; {
+		next := c.nextSibling
+		c.parent, c.prevSibling, c.nextSibling = nil, nil, nil
+		children = append(children, c)
+		c = next
+	}
+	e.firstChild, e.lastChild = nil, nil
+
+	return children
+}
+
+// detach removes e from its parent if attached
+func (e *element) detach() {
+	e.rw.RLock()
+	parent := e.parent
+	e.rw.RUnlock()
+
+	if parent != nil {
+		parent.RemoveChild(e)
+	}
+}
+
 func (e *element) RemoveChild(n Node) Node {
 	e.rw.Lock()
 	defer e.rw.Unlock()
@@ -254,14 +310,319 @@ func (e *element) GetAttributeNames() []string {
 	return names
 }
 
-func (*element) AddEventListener(eventName string, listener func(Event), args ...interface{}) {
-	// todo
+func (e *element) AddEventListener(eventType string, listener func(Event), args ...interface{}) {
+	if listener == nil {
+		return
+	}
+
+	opts := eventListenerOptionsFromArgs(args...)
+	key := listenerKey(listener)
+
+	e.rw.Lock()
+	defer e.rw.Unlock()
+
+	for _, l := range e.listeners {
+		if l.eventType == eventType && l.key == key && l.opts.Capture == opts.Capture {
+			return
+		}
+	}
+
+	e.listeners = append(e.listeners, &eventListener{
+		eventType: eventType,
+		key:       key,
+		listener:  listener,
+		opts:      opts,
+	})
+}
+
+func (e *element) RemoveEventListener(eventType string, listener func(Event), args ...interface{}) {
+	opts := eventListenerOptionsFromArgs(args...)
+	key := listenerKey(listener)
+
+	e.rw.Lock()
+	defer e.rw.Unlock()
+
+	for i, l := range e.listeners {
+		if l.eventType == eventType && l.key == key && l.opts.Capture == opts.Capture {
+			e.removeListenerAt(i)
+			return
+		}
+	}
+}
+
+func (e *element) removeListenerAt(i int) {
+	e.listeners[i].removed = true
+	e.listeners = append(e.listeners[0:i:i], e.listeners[i+1:]...)
+}
+
+// DispatchEvent https://dom.spec.whatwg.org/#concept-event-dispatch
+// only events created by NewEvent could be dispatched.
+func (e *element) DispatchEvent(evt Event) {
+	ev, ok := evt.(*event)
+	if !ok {
+		return
+	}
+
+	ev.target = e
+	ev.propagationStopped = false
+	ev.immediatePropagationStopped = false
+
+	path := make([]*element, 0)
+	for p := e.ParentNode(); p != nil; p = p.ParentNode() {
+		path = append(path, p.(*element))
+	}
+
+	defer func() {
+		ev.phase = NONE
+		ev.currentTarget = nil
+	}()
+
+	ev.phase = CAPTURING_PHASE
+	for i := len(path) - 1; i >= 0; i-- {
+		if ev.propagationStopped {
+			return
+		}
+		path[i].invokeListeners(ev, true)
+	}
+
+	if ev.propagationStopped {
+		return
+	}
+
+	ev.phase = AT_TARGET
+	e.invokeListeners(ev, true)
+	if ev.propagationStopped {
+		return
+	}
+	e.invokeListeners(ev, false)
+
+	if !ev.init.Bubbles {
+		return
+	}
+
+	ev.phase = BUBBLING_PHASE
+	for i := range path {
+		if ev.propagationStopped {
+			return
+		}
+		path[i].invokeListeners(ev, false)
+	}
+}
+
+func (e *element) invokeListeners(ev *event, capture bool) {
+	e.rw.Lock()
+	listeners := make([]*eventListener, 0, len(e.listeners))
+	for _, l := range e.listeners {
+		if l.eventType == ev.eventType && l.opts.Capture == capture {
+			listeners = append(listeners, l)
+		}
+	}
+	e.rw.Unlock()
+
+	ev.currentTarget = e
+
+	for _, l := range listeners {
+		if l.removed {
+			continue
+		}
+
+		if l.opts.Once {
+			e.rw.Lock()
+			for i := range e.listeners {
+				if e.listeners[i] == l {
+					e.removeListenerAt(i)
+					break
+				}
+			}
+			e.rw.Unlock()
+		}
+
+		ev.inPassiveListener = l.opts.Passive
+		l.listener(ev)
+		ev.inPassiveListener = false
+
+		if ev.immediatePropagationStopped {
+			return
+		}
+	}
+}
+
+func (e *element) QuerySelector(selectors string) Element {
+	sel, err := ParseSelector(selectors)
+	if err != nil {
+		return nil
+	}
+
+	var found Element
+
+	walkDescendants(e, func(n Element) bool {
+		if sel.Match(n) {
+			found = n
+			return false
+		}
+		return true
+	})
+
+	return found
+}
+
+func (e *element) QuerySelectorAll(selectors string) ElementList {
+	sel, err := ParseSelector(selectors)
+	if err != nil {
+		return nil
+	}
+
+	list := ElementList{}
+
+	walkDescendants(e, func(n Element) bool {
+		if sel.Match(n) {
+			list = append(list, n)
+		}
+		return true
+	})
+
+	return list
+}
+
+func (e *element) Matches(selectors string) bool {
+	sel, err := ParseSelector(selectors)
+	if err != nil {
+		return false
+	}
+	return sel.Match(e)
+}
+
+func (e *element) Closest(selectors string) Element {
+	sel, err := ParseSelector(selectors)
+	if err != nil {
+		return nil
+	}
+
+	for n := Element(e); n != nil; n = parentElement(n) {
+		if sel.Match(n) {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// walkDescendants walks element descendants of n in document order, until each returns false.
+func walkDescendants(n Node, each func(n Element) bool) bool {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.NodeType() != ELEMENT_NODE {
+			continue
+		}
+		if !each(c.(Element)) {
+			return false
+		}
+		if !walkDescendants(c, each) {
+			return false
+		}
+	}
+	return true
 }
 
-func (*element) RemoveEventListener(eventName string, listener func(Event), args ...interface{}) {
-	// todo
+// Get reflects className and innerHTML like the DOM of browsers, and value of select to its options,
+// others are plain properties.
+func (e *element) Get(name string) interface{} {
+	switch name {
+	case "value":
+		if e.isSelect() {
+			options := e.options()
+			for _, o := range options {
+				if selected, _ := o.Get("selected").(bool); selected {
+					return o.optionValue()
+				}
+			}
+			if len(options) > 0 {
+				return options[0].optionValue()
+			}
+			return ""
+		}
+	case "className":
+		className, _ := e.GetAttribute("class").(string)
+		return className
+	case "innerHTML":
+		b := &strings.Builder{}
+		for c := e.FirstChild(); c != nil; c = c.NextSibling() {
+			RenderToHTML(b, c)
+		}
+		return b.String()
+	}
+
+	e.rw.RLock()
+	defer e.rw.RUnlock()
+
+	return e.object.Get(name)
 }
 
-func (*element) DispatchEvent(event Event) {
-	// todo
+// Set reflects className to the class attribute, replaces children by parsing innerHTML,
+// and selects the option matched by value of select.
+func (e *element) Set(name string, value interface{}) {
+	switch name {
+	case "value":
+		if e.isSelect() {
+			v := fmt.Sprint(value)
+			for _, o := range e.options() {
+				o.Set("selected", o.optionValue() == v)
+			}
+			return
+		}
+	case "className":
+		e.SetAttribute("class", fmt.Sprint(value))
+		return
+	case "innerHTML":
+		e.takeChildren()
+		list, err := ParseFragment(strings.NewReader(fmt.Sprint(value)), e)
+		if err != nil {
+			return
+		}
+		for _, c := range list {
+			e.AppendChild(c)
+		}
+		return
+	}
+
+	e.rw.Lock()
+	defer e.rw.Unlock()
+
+	e.object.Set(name, value)
+}
+
+func (e *element) isSelect() bool {
+	return strings.EqualFold(e.NodeName(), "select")
+}
+
+// options returns option descendants of the select in order
+func (e *element) options() []*element {
+	var options []*element
+	for c := e.FirstChild(); c != nil; c = c.NextSibling() {
+		o := c.(*element)
+		switch strings.ToLower(o.NodeName()) {
+		case "option":
+			options = append(options, o)
+		case "optgroup":
+			options = append(options, o.options()...)
+		}
+	}
+	return options
+}
+
+// optionValue returns the value attribute, or the text of the option
+func (e *element) optionValue() string {
+	e.rw.RLock()
+	v, ok := e.attributes["value"]
+	e.rw.RUnlock()
+
+	if ok {
+		return fmt.Sprint(v)
+	}
+	text := &strings.Builder{}
+	for c := e.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.NodeType() == TEXT_NODE {
+			text.WriteString(c.TextContent())
+		}
+	}
+	return text.String()
 }
@@ -1,6 +1,11 @@
 package dom
 
+import "unsafe"
+
 // EventTarget https://developer.mozilla.org/en-US/docs/Web/API/EventTarget
+// Listeners are identified by the func value like functions in js,
+// so remove the listener by the value added, not by evaluating the func literal or method value again,
+// which creates a new func like bind in js.
 type EventTarget interface {
 	AddEventListener(eventName string, listener func(event Event), args ...interface{})
 	RemoveEventListener(eventName string, listener func(event Event), args ...interface{})
@@ -11,8 +16,83 @@ type EventTarget interface {
 type Event interface {
 	JSObject
 
+	Type() string
 	Target() EventTarget
+	CurrentTarget() EventTarget
+	EventPhase() EventPhase
+	Bubbles() bool
+	DefaultPrevented() bool
 	PreventDefault()
 	StopImmediatePropagation()
 	StopPropagation()
 }
+
+type EventPhase uint32
+
+// https://developer.mozilla.org/en-US/docs/Web/API/Event/eventPhase
+const (
+	NONE EventPhase = iota
+	CAPTURING_PHASE
+	AT_TARGET
+	BUBBLING_PHASE
+)
+
+// EventInit https://developer.mozilla.org/en-US/docs/Web/API/Event/Event#options
+type EventInit struct {
+	Bubbles    bool
+	Cancelable bool
+	Composed   bool
+}
+
+func (o EventInit) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"bubbles":    o.Bubbles,
+		"cancelable": o.Cancelable,
+		"composed":   o.Composed,
+	}
+}
+
+// EventListenerOptions https://developer.mozilla.org/en-US/docs/Web/API/EventTarget/addEventListener#options
+// could be passed as the variadic args of AddEventListener and RemoveEventListener,
+// as well as bool for useCapture or map[string]interface{} like js.
+type EventListenerOptions struct {
+	Capture bool
+	Once    bool
+	Passive bool
+}
+
+func (o EventListenerOptions) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"capture": o.Capture,
+		"once":    o.Once,
+		"passive": o.Passive,
+	}
+}
+
+func eventListenerOptionsFromArgs(args ...interface{}) (opts EventListenerOptions) {
+	if len(args) == 0 {
+		return
+	}
+
+	switch x := args[0].(type) {
+	case bool:
+		opts.Capture = x
+	case EventListenerOptions:
+		opts = x
+	case *EventListenerOptions:
+		opts = *x
+	case map[string]interface{}:
+		opts.Capture, _ = x["capture"].(bool)
+		opts.Once, _ = x["once"].(bool)
+		opts.Passive, _ = x["passive"].(bool)
+	}
+
+	return
+}
+
+// listenerKey returns the identity of listener,
+// func values are not comparable, so use the pointer of its closure,
+// which is kept by copies of the func value, and differs between evaluations of method values.
+func listenerKey(listener func(event Event)) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&listener))
+}
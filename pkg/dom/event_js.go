@@ -4,9 +4,19 @@
 package dom
 
 import (
+	"syscall/js"
+
 	"github.com/go-courier/gox/pkg/jsgo"
 )
 
+// NewEvent https://developer.mozilla.org/en-US/docs/Web/API/Event/Event
+func NewEvent(eventType string, init ...EventInit) Event {
+	if len(init) > 0 {
+		return &jsEvent{JSValue: js.Global().Get("Event").New(eventType, init[0].Map())}
+	}
+	return &jsEvent{JSValue: js.Global().Get("Event").New(eventType)}
+}
+
 type jsEvent struct {
 	JSValue
 }
@@ -19,10 +29,30 @@ func (e *jsEvent) Set(propName string, v interface{}) {
 	e.JSValue.Set(propName, v)
 }
 
+func (e *jsEvent) Type() string {
+	return e.JSValue.Get("type").String()
+}
+
 func (e *jsEvent) Target() EventTarget {
 	return asElement(e.JSValue.Get("target"))
 }
 
+func (e *jsEvent) CurrentTarget() EventTarget {
+	return asElement(e.JSValue.Get("currentTarget"))
+}
+
+func (e *jsEvent) EventPhase() EventPhase {
+	return EventPhase(e.JSValue.Get("eventPhase").Int())
+}
+
+func (e *jsEvent) Bubbles() bool {
+	return e.JSValue.Get("bubbles").Bool()
+}
+
+func (e *jsEvent) DefaultPrevented() bool {
+	return e.JSValue.Get("defaultPrevented").Bool()
+}
+
 func (e *jsEvent) PreventDefault() {
 	e.Call("preventDefault")
 }
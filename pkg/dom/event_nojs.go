@@ -0,0 +1,75 @@
+//go:build !js
+// +build !js
+
+package dom
+
+// NewEvent https://developer.mozilla.org/en-US/docs/Web/API/Event/Event
+func NewEvent(eventType string, init ...EventInit) Event {
+	e := &event{eventType: eventType}
+	if len(init) > 0 {
+		e.init = init[0]
+	}
+	return e
+}
+
+type event struct {
+	eventType     string
+	init          EventInit
+	target        EventTarget
+	currentTarget EventTarget
+	phase         EventPhase
+
+	defaultPrevented            bool
+	propagationStopped          bool
+	immediatePropagationStopped bool
+	inPassiveListener           bool
+
+	object
+}
+
+func (e *event) Type() string {
+	return e.eventType
+}
+
+func (e *event) Target() EventTarget {
+	return e.target
+}
+
+func (e *event) CurrentTarget() EventTarget {
+	return e.currentTarget
+}
+
+func (e *event) EventPhase() EventPhase {
+	return e.phase
+}
+
+func (e *event) Bubbles() bool {
+	return e.init.Bubbles
+}
+
+func (e *event) DefaultPrevented() bool {
+	return e.defaultPrevented
+}
+
+func (e *event) PreventDefault() {
+	if e.init.Cancelable && !e.inPassiveListener {
+		e.defaultPrevented = true
+	}
+}
+
+func (e *event) StopPropagation() {
+	e.propagationStopped = true
+}
+
+func (e *event) StopImmediatePropagation() {
+	e.propagationStopped = true
+	e.immediatePropagationStopped = true
+}
+
+type eventListener struct {
+	eventType string
+	key       uintptr
+	listener  func(event Event)
+	opts      EventListenerOptions
+	removed   bool
+}
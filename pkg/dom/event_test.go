@@ -0,0 +1,100 @@
+package dom
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestEventTarget(t *testing.T) {
+	p := Document.CreateElement("parent")
+	c := Document.CreateElement("child")
+	p.AppendChild(c)
+
+	calls := make([]string, 0)
+
+	record := func(name string) func(e Event) {
+		return func(e Event) {
+			calls = append(calls, name)
+		}
+	}
+
+	t.Run("should dispatch through capture and bubble phases", func(t *testing.T) {
+		calls = calls[0:0]
+
+		p.AddEventListener("click", record("parent capture"), true)
+		p.AddEventListener("click", record("parent bubble"))
+		c.AddEventListener("click", record("child"))
+
+		c.DispatchEvent(NewEvent("click", EventInit{Bubbles: true}))
+		NewWithT(t).Expect(calls).To(Equal([]string{"parent capture", "child", "parent bubble"}))
+
+		calls = calls[0:0]
+
+		c.DispatchEvent(NewEvent("click"))
+		NewWithT(t).Expect(calls).To(Equal([]string{"parent capture", "child"}))
+	})
+
+	t.Run("should remove listener", func(t *testing.T) {
+		calls = calls[0:0]
+
+		n := Document.CreateElement("div")
+		l1 := record("1")
+		l2 := record("2")
+
+		n.AddEventListener("click", l1)
+		n.AddEventListener("click", l2)
+		// same listener only added once
+		n.AddEventListener("click", l2)
+		n.RemoveEventListener("click", l1)
+		// capture not matched
+		n.RemoveEventListener("click", l2, true)
+
+		n.DispatchEvent(NewEvent("click"))
+		NewWithT(t).Expect(calls).To(Equal([]string{"2"}))
+	})
+
+	t.Run("should support once and passive", func(t *testing.T) {
+		calls = calls[0:0]
+
+		n := Document.CreateElement("div")
+
+		n.AddEventListener("click", func(e Event) {
+			calls = append(calls, "once")
+			e.PreventDefault()
+		}, EventListenerOptions{Once: true, Passive: true})
+
+		e := NewEvent("click", EventInit{Cancelable: true})
+		n.DispatchEvent(e)
+		n.DispatchEvent(NewEvent("click"))
+
+		NewWithT(t).Expect(calls).To(Equal([]string{"once"}))
+		NewWithT(t).Expect(e.DefaultPrevented()).To(BeFalse())
+	})
+
+	t.Run("should stop propagation", func(t *testing.T) {
+		calls = calls[0:0]
+
+		n := Document.CreateElement("div")
+		child := Document.CreateElement("span")
+		n.AppendChild(child)
+
+		n.AddEventListener("click", record("parent"))
+		child.AddEventListener("click", func(e Event) {
+			calls = append(calls, "stop")
+			NewWithT(t).Expect(e.EventPhase()).To(Equal(AT_TARGET))
+			NewWithT(t).Expect(e.CurrentTarget()).To(Equal(child))
+			e.PreventDefault()
+			e.StopImmediatePropagation()
+		})
+		child.AddEventListener("click", record("child"))
+
+		e := NewEvent("click", EventInit{Bubbles: true, Cancelable: true})
+		child.DispatchEvent(e)
+
+		NewWithT(t).Expect(calls).To(Equal([]string{"stop"}))
+		NewWithT(t).Expect(e.DefaultPrevented()).To(BeTrue())
+		NewWithT(t).Expect(e.Target()).To(Equal(child))
+		NewWithT(t).Expect(e.EventPhase()).To(Equal(NONE))
+	})
+}
@@ -4,33 +4,242 @@ import (
 	"fmt"
 	"io"
 	"sort"
-	"strconv"
 	"strings"
 )
 
-func RenderToHTML(w io.Writer, n Element) {
+// HTMLOption customizes html serialization of RenderToHTML
+type HTMLOption func(s *htmlSerializer)
+
+// WithIndent pretty-prints html, elements which only contain elements or comments
+// are broken into lines and indented by indent.
+// Text is never touched, so inline elements mixed with text are kept in one line.
+func WithIndent(indent string) HTMLOption {
+	return func(s *htmlSerializer) {
+		s.indent = indent
+	}
+}
+
+// RenderToHTML writes the html of n, follows https://html.spec.whatwg.org/#serialising-html-fragments
+func RenderToHTML(w io.Writer, n Node, options ...HTMLOption) {
+	s := &htmlSerializer{w: w}
+	for _, option := range options {
+		option(s)
+	}
+	s.renderNode(n, 0)
+}
+
+type htmlSerializer struct {
+	w      io.Writer
+	indent string
+}
+
+func (s *htmlSerializer) renderNode(n Node, depth int) {
 	switch n.NodeType() {
 	case TEXT_NODE:
-		_, _ = io.WriteString(w, n.TextContent())
+		if p := n.ParentNode(); p != nil && p.NodeType() == ELEMENT_NODE && IsRawTextElement(p.NodeName()) {
+			WriteRawText(s.w, n.TextContent())
+			return
+		}
+		WriteText(s.w, n.TextContent())
+	case COMMENT_NODE:
+		WriteComment(s.w, n.TextContent())
+	case DOCUMENT_TYPE_NODE:
+		WriteDoctype(s.w, n.NodeName())
+	case DOCUMENT_NODE, DOCUMENT_FRAGMENT_NODE:
+		s.renderChildren(n, depth-1)
 	case ELEMENT_NODE:
-		_, _ = fmt.Fprintf(w, "<%s", n.NodeName())
+		e, ok := n.(Element)
+		if !ok {
+			return
+		}
 
-		if names := n.GetAttributeNames(); len(names) > 0 {
-			sort.Strings(names)
+		attrs := map[string]interface{}{}
 
-			for _, k := range names {
-				_, _ = fmt.Fprintf(w, " %s=%s", k, strconv.Quote(stringify(n.GetAttribute(k))))
-			}
+		for _, k := range e.GetAttributeNames() {
+			attrs[k] = e.GetAttribute(k)
 		}
 
-		_, _ = io.WriteString(w, ">")
+		tagName := e.NodeName()
+
+		WriteStartTag(s.w, tagName, attrs)
+
+		if IsVoidElement(tagName) {
+			return
+		}
+
+		s.renderChildren(n, depth)
+
+		WriteEndTag(s.w, tagName)
+	}
+}
 
+func (s *htmlSerializer) renderChildren(n Node, depth int) {
+	if s.indent == "" || !s.canIndent(n) {
 		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
-			RenderToHTML(w, c.(Element))
+			s.renderNode(c, depth+1)
+		}
+		return
+	}
+
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if depth >= 0 || c != n.FirstChild() {
+			s.newLine(depth + 1)
+		}
+		s.renderNode(c, depth+1)
+	}
+
+	if depth >= 0 {
+		s.newLine(depth)
+	}
+}
+
+// canIndent returns true when n only contains elements or comments,
+// whitespaces in pre, textarea or raw text elements are significant.
+func (s *htmlSerializer) canIndent(n Node) bool {
+	if n.NodeType() == ELEMENT_NODE {
+		switch name := strings.ToLower(n.NodeName()); name {
+		case "pre", "textarea", "listing":
+			return false
+		default:
+			if IsRawTextElement(name) {
+				return false
+			}
+		}
+	}
+
+	if n.FirstChild() == nil {
+		return false
+	}
+
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.NodeType() == TEXT_NODE {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *htmlSerializer) newLine(depth int) {
+	_, _ = io.WriteString(s.w, "\n"+strings.Repeat(s.indent, depth))
+}
+
+// https://html.spec.whatwg.org/#void-elements
+var voidElements = map[string]bool{
+	"area":     true,
+	"base":     true,
+	"basefont": true,
+	"bgsound":  true,
+	"br":       true,
+	"col":      true,
+	"embed":    true,
+	"frame":    true,
+	"hr":       true,
+	"img":      true,
+	"input":    true,
+	"keygen":   true,
+	"link":     true,
+	"meta":     true,
+	"param":    true,
+	"source":   true,
+	"track":    true,
+	"wbr":      true,
+}
+
+// IsVoidElement returns true when the element has no end tag and children, like br and input
+func IsVoidElement(tagName string) bool {
+	return voidElements[strings.ToLower(tagName)]
+}
+
+// elements of which text is serialized without escaping
+var rawTextElements = map[string]bool{
+	"style":     true,
+	"script":    true,
+	"xmp":       true,
+	"iframe":    true,
+	"noembed":   true,
+	"noframes":  true,
+	"plaintext": true,
+}
+
+// IsRawTextElement returns true when text of the element is written as is, like script and style
+func IsRawTextElement(tagName string) bool {
+	return rawTextElements[strings.ToLower(tagName)]
+}
+
+var textEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"\u00a0", "&nbsp;",
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+var attrEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"\u00a0", "&nbsp;",
+	`"`, "&quot;",
+)
+
+// WriteStartTag writes the start tag of tagName with attrs sorted by name.
+// Attributes of nil or false are omitted, and true is written as empty value as boolean attribute.
+func WriteStartTag(w io.Writer, tagName string, attrs map[string]interface{}) {
+	_, _ = fmt.Fprintf(w, "<%s", tagName)
+
+	if len(attrs) > 0 {
+		names := make([]string, 0, len(attrs))
+		for k := range attrs {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		for _, k := range names {
+			if value, ok := AttrValue(k, attrs[k]); ok {
+				_, _ = fmt.Fprintf(w, ` %s="%s"`, k, attrEscaper.Replace(value))
+			}
 		}
+	}
+
+	_, _ = io.WriteString(w, ">")
+}
+
+// AttrValue returns the value of attribute name written by WriteStartTag,
+// false when the attribute is omitted.
+func AttrValue(name string, v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case nil:
+		return "", false
+	case bool:
+		return "", x
+	}
+	if style, ok := StyleMapOf(v); ok && name == "style" {
+		return StyleString(style), true
+	}
+	return stringify(v), true
+}
 
-		_, _ = fmt.Fprintf(w, "</%s>", n.NodeName())
+// WriteEndTag writes the end tag of tagName, void elements have no end tag
+func WriteEndTag(w io.Writer, tagName string) {
+	if IsVoidElement(tagName) {
+		return
 	}
+	_, _ = fmt.Fprintf(w, "</%s>", tagName)
+}
+
+// WriteText writes text escaped
+func WriteText(w io.Writer, text string) {
+	_, _ = textEscaper.WriteString(w, text)
+}
+
+// WriteRawText writes text of raw text elements as is
+func WriteRawText(w io.Writer, text string) {
+	_, _ = io.WriteString(w, text)
+}
+
+func WriteComment(w io.Writer, data string) {
+	_, _ = fmt.Fprintf(w, "<!--%s-->", data)
+}
+
+func WriteDoctype(w io.Writer, name string) {
+	_, _ = fmt.Fprintf(w, "<!DOCTYPE %s>", name)
 }
 
 func stringify(v interface{}) string {
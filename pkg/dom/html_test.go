@@ -0,0 +1,72 @@
+package dom
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRenderToHTML(t *testing.T) {
+	html := func(n Node, options ...HTMLOption) string {
+		buf := bytes.NewBuffer(nil)
+		RenderToHTML(buf, n, options...)
+		return buf.String()
+	}
+
+	t.Run("should escape text and attributes", func(t *testing.T) {
+		div := Document.CreateElement("div")
+		div.SetAttribute("title", "a \"b\" & <c> ")
+		div.AppendChild(Document.CreateTextNode("<b>\"x\" & 'y' </b>"))
+
+		NewWithT(t).Expect(html(div)).To(Equal(`<div title="a &quot;b&quot; &amp; <c>&nbsp;">&lt;b&gt;"x" &amp; 'y'&nbsp;&lt;/b&gt;</div>`))
+	})
+
+	t.Run("should write void elements without end tag", func(t *testing.T) {
+		p := Document.CreateElement("p")
+		p.AppendChild(Document.CreateTextNode("a"))
+		p.AppendChild(Document.CreateElement("br"))
+		input := Document.CreateElement("input")
+		input.SetAttribute("value", "b")
+		p.AppendChild(input)
+
+		NewWithT(t).Expect(html(p)).To(Equal(`<p>a<br><input value="b"></p>`))
+	})
+
+	t.Run("should write text of raw text elements as is", func(t *testing.T) {
+		script := Document.CreateElement("script")
+		script.AppendChild(Document.CreateTextNode("if (a < b && c) {}"))
+
+		textarea := Document.CreateElement("textarea")
+		textarea.AppendChild(Document.CreateTextNode("</textarea>"))
+
+		NewWithT(t).Expect(html(script)).To(Equal(`<script>if (a < b && c) {}</script>`))
+		NewWithT(t).Expect(html(textarea)).To(Equal(`<textarea>&lt;/textarea&gt;</textarea>`))
+	})
+
+	t.Run("should write boolean attributes", func(t *testing.T) {
+		input := Document.CreateElement("input")
+		input.SetAttribute("disabled", true)
+		input.SetAttribute("checked", false)
+		input.SetAttribute("readonly", nil)
+
+		NewWithT(t).Expect(html(input)).To(Equal(`<input disabled="">`))
+	})
+
+	t.Run("should pretty print", func(t *testing.T) {
+		ul := Document.CreateElement("ul")
+		for _, text := range []string{"1", "2"} {
+			li := Document.CreateElement("li")
+			li.AppendChild(Document.CreateTextNode(text))
+			ul.AppendChild(li)
+		}
+		pre := Document.CreateElement("pre")
+		pre.AppendChild(Document.CreateElement("b"))
+		ul.AppendChild(pre)
+
+		div := Document.CreateElement("div")
+		div.AppendChild(ul)
+
+		NewWithT(t).Expect(html(div, WithIndent("  "))).To(Equal("<div>\n  <ul>\n    <li>1</li>\n    <li>2</li>\n    <pre><b></b></pre>\n  </ul>\n</div>"))
+	})
+}
@@ -0,0 +1,76 @@
+package dom
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ParseHTML parses the html document from r, and returns the document node,
+// which always contains html, head and body, even they are omitted in the html.
+func ParseHTML(r io.Reader) (Element, error) {
+	n, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := createDocumentNode()
+	appendHTMLNodes(doc, n)
+	return doc, nil
+}
+
+// ParseFragment parses html as children of context like setting innerHTML,
+// body is used when context is nil.
+func ParseFragment(r io.Reader, context Element) (ElementList, error) {
+	c := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	if context != nil {
+		name := strings.ToLower(context.NodeName())
+		c.Data, c.DataAtom = name, atom.Lookup([]byte(name))
+	}
+
+	nodes, err := html.ParseFragment(r, c)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make(ElementList, 0, len(nodes))
+	for _, n := range nodes {
+		if e := fromHTMLNode(n); e != nil {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+func appendHTMLNodes(parent Element, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if e := fromHTMLNode(c); e != nil {
+			parent.AppendChild(e)
+		}
+	}
+}
+
+func fromHTMLNode(n *html.Node) Element {
+	switch n.Type {
+	case html.TextNode:
+		return Document.CreateTextNode(n.Data)
+	case html.CommentNode:
+		return Document.CreateComment(n.Data)
+	case html.DoctypeNode:
+		return Document.CreateDocumentType(n.Data)
+	case html.ElementNode:
+		e := Document.CreateElement(n.Data)
+		for _, a := range n.Attr {
+			name := a.Key
+			if a.Namespace != "" {
+				name = a.Namespace + ":" + a.Key
+			}
+			e.SetAttribute(name, a.Val)
+		}
+		appendHTMLNodes(e, n)
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package dom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseHTML(t *testing.T) {
+	html := func(n Node) string {
+		buf := bytes.NewBuffer(nil)
+		RenderToHTML(buf, n)
+		return buf.String()
+	}
+
+	t.Run("should parse document", func(t *testing.T) {
+		doc, err := ParseHTML(strings.NewReader(`<title>a &amp; b</title><!-- note --><p class="x" hidden>hello<br>world`))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		NewWithT(t).Expect(doc.NodeType()).To(Equal(DOCUMENT_NODE))
+		NewWithT(t).Expect(html(doc)).To(Equal(`<html><head><title>a &amp; b</title><!-- note --></head><body><p class="x" hidden="">hello<br>world</p></body></html>`))
+
+		p := doc.QuerySelector("body > p.x")
+		NewWithT(t).Expect(p).NotTo(BeNil())
+		NewWithT(t).Expect(p.GetAttribute("hidden")).To(Equal(""))
+		NewWithT(t).Expect(p.FirstChild().TextContent()).To(Equal("hello"))
+
+		comment := doc.QuerySelector("head").LastChild()
+		NewWithT(t).Expect(comment.NodeType()).To(Equal(COMMENT_NODE))
+		NewWithT(t).Expect(comment.NodeName()).To(Equal("#comment"))
+		NewWithT(t).Expect(comment.TextContent()).To(Equal(" note "))
+	})
+
+	t.Run("should parse doctype", func(t *testing.T) {
+		doc, err := ParseHTML(strings.NewReader(`<!DOCTYPE html><p>1</p>`))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(doc.FirstChild().NodeType()).To(Equal(DOCUMENT_TYPE_NODE))
+		NewWithT(t).Expect(html(doc)).To(Equal(`<!DOCTYPE html><html><head></head><body><p>1</p></body></html>`))
+	})
+
+	t.Run("should parse fragment", func(t *testing.T) {
+		list, err := ParseFragment(strings.NewReader(`<b>1</b>2<!--3-->`), nil)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(list).To(HaveLen(3))
+		NewWithT(t).Expect(html(list[0])).To(Equal(`<b>1</b>`))
+		NewWithT(t).Expect(list[1].TextContent()).To(Equal("2"))
+		NewWithT(t).Expect(list[2].NodeType()).To(Equal(COMMENT_NODE))
+	})
+
+	t.Run("should parse fragment in context", func(t *testing.T) {
+		list, err := ParseFragment(strings.NewReader(`<tr><td>1</td></tr>`), Document.CreateElement("tbody"))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(list).To(HaveLen(1))
+		NewWithT(t).Expect(html(list[0])).To(Equal(`<tr><td>1</td></tr>`))
+
+		// tr is dropped out of table
+		list, err = ParseFragment(strings.NewReader(`<tr><td>1</td></tr>`), nil)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(html(list[0])).To(Equal(`1`))
+	})
+}
@@ -0,0 +1,735 @@
+package dom
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Selector is the parsed selector list
+// https://developer.mozilla.org/en-US/docs/Web/CSS/CSS_Selectors
+//
+// supports:
+//
+//	type, universal, #id, .class
+//	[attr], [attr=v], [attr~=v], [attr|=v], [attr^=v], [attr$=v], [attr*=v] with optional i flag
+//	combinators descendant, >, +, ~
+//	:first-child, :last-child, :only-child, :nth-child(), :nth-last-child()
+//	:first-of-type, :last-of-type, :only-of-type, :nth-of-type(), :nth-last-of-type()
+//	:not(), :is(), :empty, :root
+type Selector []*complexSelector
+
+// maxCachedSelectors bounds the parsed selectors cache,
+// the least recently used one is dropped when it is full.
+const maxCachedSelectors = 256
+
+var selectors = &selectorCache{max: maxCachedSelectors}
+
+type selectorCache struct {
+	mu    sync.Mutex
+	max   int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type selectorCacheEntry struct {
+	key string
+	sel Selector
+}
+
+func (c *selectorCache) Load(s string) (Selector, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[s]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*selectorCacheEntry).sel, true
+	}
+	return nil, false
+}
+
+func (c *selectorCache) Store(s string, sel Selector) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		c.ll = list.New()
+		c.items = map[string]*list.Element{}
+	}
+
+	if el, ok := c.items[s]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*selectorCacheEntry).sel = sel
+		return
+	}
+
+	c.items[s] = c.ll.PushFront(&selectorCacheEntry{key: s, sel: sel})
+
+	for c.ll.Len() > c.max {
+		last := c.ll.Back()
+		c.ll.Remove(last)
+		delete(c.items, last.Value.(*selectorCacheEntry).key)
+	}
+}
+
+func (c *selectorCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// ParseSelector parses selector list, parsed selectors are cached.
+func ParseSelector(s string) (Selector, error) {
+	if sel, ok := selectors.Load(s); ok {
+		return sel, nil
+	}
+
+	rules, err := splitSelectorList(s)
+	if err != nil {
+		return nil, fmt.Errorf("dom: invalid selector %q: %s", s, err)
+	}
+
+	sel := make(Selector, len(rules))
+
+	for i := range rules {
+		cs, err := parseComplexSelector(rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("dom: invalid selector %q: %s", s, err)
+		}
+		sel[i] = cs
+	}
+
+	selectors.Store(s, sel)
+
+	return sel, nil
+}
+
+// splitSelectorList splits s by top level commas,
+// an empty selector in the list is a syntax error as browsers do.
+func splitSelectorList(s string) ([]string, error) {
+	p := &selectorParser{s: s}
+
+	rules := make([]string, 0)
+	start := 0
+
+	collect := func(end int) error {
+		rule := strings.TrimSpace(s[start:end])
+		if rule == "" {
+			if len(rules) == 0 && end == len(s) {
+				return fmt.Errorf("empty")
+			}
+			return fmt.Errorf("empty selector in list")
+		}
+		rules = append(rules, rule)
+		return nil
+	}
+
+	for !p.eof() {
+		switch c := p.peek(); c {
+		case '\\':
+			p.pos += 2
+		case '"', '\'':
+			end := strings.IndexByte(p.s[p.pos+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed string")
+			}
+			p.pos += end + 2
+		case '(', '[':
+			p.pos++
+			close := byte(')')
+			if c == '[' {
+				close = ']'
+			}
+			if _, err := p.until(close); err != nil {
+				return nil, err
+			}
+		case ',':
+			if err := collect(p.pos); err != nil {
+				return nil, err
+			}
+			p.pos++
+			start = p.pos
+		default:
+			p.pos++
+		}
+	}
+
+	if err := collect(len(s)); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Match returns true when any selector of the list matches e.
+func (sel Selector) Match(e Element) bool {
+	if e == nil || e.NodeType() != ELEMENT_NODE {
+		return false
+	}
+	for i := range sel {
+		if sel[i].match(e, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+type complexSelector struct {
+	// compounds from right to left
+	compounds []*compoundSelector
+	// combinators[i] is the combinator between compounds[i] and compounds[i+1]
+	combinators []byte
+}
+
+func (cs *complexSelector) match(e Element, i int) bool {
+	if !cs.compounds[i].match(e) {
+		return false
+	}
+
+	if i == len(cs.compounds)-1 {
+		return true
+	}
+
+	switch cs.combinators[i] {
+	case '>':
+		if p := parentElement(e); p != nil {
+			return cs.match(p, i+1)
+		}
+	case '+':
+		if p := previousElementSibling(e); p != nil {
+			return cs.match(p, i+1)
+		}
+	case '~':
+		for p := previousElementSibling(e); p != nil; p = previousElementSibling(p) {
+			if cs.match(p, i+1) {
+				return true
+			}
+		}
+	default:
+		for p := parentElement(e); p != nil; p = parentElement(p) {
+			if cs.match(p, i+1) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type compoundSelector struct {
+	tagName  string
+	matchers []func(e Element) bool
+}
+
+func (c *compoundSelector) match(e Element) bool {
+	if c.tagName != "" && c.tagName != "*" && !strings.EqualFold(c.tagName, e.NodeName()) {
+		return false
+	}
+	for i := range c.matchers {
+		if !c.matchers[i](e) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseComplexSelector(s string) (*complexSelector, error) {
+	p := &selectorParser{s: s}
+
+	compounds := make([]*compoundSelector, 0)
+	combinators := make([]byte, 0)
+
+	for {
+		start := p.pos
+		p.skipSpaces()
+
+		if p.eof() {
+			break
+		}
+
+		if len(compounds) > 0 {
+			comb := byte(' ')
+			if c := p.peek(); c == '>' || c == '+' || c == '~' {
+				comb = c
+				p.pos++
+				p.skipSpaces()
+			} else if p.pos == start {
+				return nil, fmt.Errorf("unexpected %q", c)
+			}
+			combinators = append(combinators, comb)
+		}
+
+		c, err := p.compound()
+		if err != nil {
+			return nil, err
+		}
+		compounds = append(compounds, c)
+	}
+
+	if len(compounds) == 0 || len(combinators) != len(compounds)-1 {
+		return nil, fmt.Errorf("incomplete selector")
+	}
+
+	// reverse to match from right
+	cs := &complexSelector{}
+	for i := len(compounds) - 1; i >= 0; i-- {
+		cs.compounds = append(cs.compounds, compounds[i])
+	}
+	for i := len(combinators) - 1; i >= 0; i-- {
+		cs.combinators = append(cs.combinators, combinators[i])
+	}
+	return cs, nil
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *selectorParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *selectorParser) skipSpaces() {
+	for !p.eof() {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r', '\f':
+			p.pos++
+			continue
+		}
+		break
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *selectorParser) ident() (string, error) {
+	b := &strings.Builder{}
+
+	for !p.eof() {
+		c := p.s[p.pos]
+		if c == '\\' && p.pos+1 < len(p.s) {
+			b.WriteByte(p.s[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if !isIdentChar(c) {
+			break
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+
+	if b.Len() == 0 {
+		if p.eof() {
+			return "", fmt.Errorf("unexpected end")
+		}
+		return "", fmt.Errorf("unexpected %q", p.peek())
+	}
+
+	return b.String(), nil
+}
+
+// until reads to the matched close bracket
+func (p *selectorParser) until(close byte) (string, error) {
+	open := p.s[p.pos-1]
+	depth := 0
+	start := p.pos
+
+	for !p.eof() {
+		c := p.s[p.pos]
+		switch c {
+		case '\\':
+			p.pos++
+		case '"', '\'':
+			end := strings.IndexByte(p.s[p.pos+1:], c)
+			if end < 0 {
+				return "", fmt.Errorf("unclosed string")
+			}
+			p.pos += end + 1
+		case open:
+			depth++
+		case close:
+			if depth == 0 {
+				v := p.s[start:p.pos]
+				p.pos++
+				return v, nil
+			}
+			depth--
+		}
+		p.pos++
+	}
+
+	return "", fmt.Errorf("missing %q", close)
+}
+
+func (p *selectorParser) compound() (*compoundSelector, error) {
+	c := &compoundSelector{}
+
+	if ch := p.peek(); ch == '*' {
+		c.tagName = "*"
+		p.pos++
+	} else if isIdentChar(ch) || ch == '\\' {
+		tagName, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		c.tagName = tagName
+	}
+
+	for !p.eof() {
+		switch ch := p.peek(); ch {
+		case '#':
+			p.pos++
+			id, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			c.matchers = append(c.matchers, func(e Element) bool {
+				return attrValue(e, "id") == id
+			})
+		case '.':
+			p.pos++
+			class, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			c.matchers = append(c.matchers, func(e Element) bool {
+				return includesWord(attrValue(e, "class"), class)
+			})
+		case '[':
+			p.pos++
+			content, err := p.until(']')
+			if err != nil {
+				return nil, err
+			}
+			m, err := parseAttrMatcher(content)
+			if err != nil {
+				return nil, err
+			}
+			c.matchers = append(c.matchers, m)
+		case ':':
+			p.pos++
+			if p.peek() == ':' {
+				return nil, fmt.Errorf("pseudo elements are not supported")
+			}
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			args := ""
+			hasArgs := false
+			if p.peek() == '(' {
+				p.pos++
+				hasArgs = true
+				if args, err = p.until(')'); err != nil {
+					return nil, err
+				}
+			}
+			m, err := parsePseudoClass(strings.ToLower(name), strings.TrimSpace(args), hasArgs)
+			if err != nil {
+				return nil, err
+			}
+			c.matchers = append(c.matchers, m)
+		case ' ', '\t', '\n', '\r', '\f', '>', '+', '~':
+			return c.validate(ch)
+		default:
+			return nil, fmt.Errorf("unexpected %q", ch)
+		}
+	}
+
+	return c.validate(0)
+}
+
+func (c *compoundSelector) validate(next byte) (*compoundSelector, error) {
+	if c.tagName == "" && len(c.matchers) == 0 {
+		if next == 0 {
+			return nil, fmt.Errorf("unexpected end")
+		}
+		return nil, fmt.Errorf("unexpected %q", next)
+	}
+	return c, nil
+}
+
+func parseAttrMatcher(content string) (func(e Element) bool, error) {
+	p := &selectorParser{s: strings.TrimSpace(content)}
+
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+
+	if p.eof() {
+		return func(e Element) bool {
+			return hasAttr(e, name)
+		}, nil
+	}
+
+	op := ""
+	switch ch := p.peek(); ch {
+	case '=':
+		op = "="
+		p.pos++
+	case '~', '|', '^', '$', '*':
+		p.pos++
+		if p.peek() != '=' {
+			return nil, fmt.Errorf("invalid attribute selector [%s]", content)
+		}
+		p.pos++
+		op = string(ch) + "="
+	default:
+		return nil, fmt.Errorf("invalid attribute selector [%s]", content)
+	}
+
+	p.skipSpaces()
+
+	value := ""
+	if ch := p.peek(); ch == '"' || ch == '\'' {
+		end := strings.IndexByte(p.s[p.pos+1:], ch)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed string in [%s]", content)
+		}
+		value = p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else {
+		if value, err = p.ident(); err != nil {
+			return nil, err
+		}
+	}
+
+	p.skipSpaces()
+
+	caseInsensitive := false
+	if ch := p.peek(); ch == 'i' || ch == 'I' {
+		caseInsensitive = true
+		p.pos++
+		p.skipSpaces()
+	}
+
+	if !p.eof() {
+		return nil, fmt.Errorf("invalid attribute selector [%s]", content)
+	}
+
+	if caseInsensitive {
+		value = strings.ToLower(value)
+	}
+
+	return func(e Element) bool {
+		if !hasAttr(e, name) {
+			return false
+		}
+
+		v := attrValue(e, name)
+		if caseInsensitive {
+			v = strings.ToLower(v)
+		}
+
+		switch op {
+		case "=":
+			return v == value
+		case "~=":
+			return includesWord(v, value)
+		case "|=":
+			return v == value || strings.HasPrefix(v, value+"-")
+		case "^=":
+			return value != "" && strings.HasPrefix(v, value)
+		case "$=":
+			return value != "" && strings.HasSuffix(v, value)
+		case "*=":
+			return value != "" && strings.Contains(v, value)
+		}
+		return false
+	}, nil
+}
+
+func parsePseudoClass(name string, args string, hasArgs bool) (func(e Element) bool, error) {
+	if hasArgs {
+		switch name {
+		case "not", "is":
+			sel, err := ParseSelector(args)
+			if err != nil {
+				return nil, err
+			}
+			if name == "not" {
+				return func(e Element) bool {
+					return !sel.Match(e)
+				}, nil
+			}
+			return sel.Match, nil
+		case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+			a, b, err := parseNth(args)
+			if err != nil {
+				return nil, err
+			}
+			ofType := strings.HasSuffix(name, "of-type")
+			fromEnd := strings.HasPrefix(name, "nth-last")
+			return func(e Element) bool {
+				return matchNth(a, b, elementIndex(e, ofType, fromEnd))
+			}, nil
+		}
+		return nil, fmt.Errorf("unsupported pseudo class :%s()", name)
+	}
+
+	switch name {
+	case "first-child":
+		return func(e Element) bool { return elementIndex(e, false, false) == 1 }, nil
+	case "last-child":
+		return func(e Element) bool { return elementIndex(e, false, true) == 1 }, nil
+	case "only-child":
+		return func(e Element) bool {
+			return elementIndex(e, false, false) == 1 && elementIndex(e, false, true) == 1
+		}, nil
+	case "first-of-type":
+		return func(e Element) bool { return elementIndex(e, true, false) == 1 }, nil
+	case "last-of-type":
+		return func(e Element) bool { return elementIndex(e, true, true) == 1 }, nil
+	case "only-of-type":
+		return func(e Element) bool {
+			return elementIndex(e, true, false) == 1 && elementIndex(e, true, true) == 1
+		}, nil
+	case "empty":
+		return func(e Element) bool {
+			for c := e.FirstChild(); c != nil; c = c.NextSibling() {
+				if c.NodeType() == ELEMENT_NODE || (c.NodeType() == TEXT_NODE && c.TextContent() != "") {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case "root":
+		return func(e Element) bool { return parentElement(e) == nil }, nil
+	}
+
+	return nil, fmt.Errorf("unsupported pseudo class :%s", name)
+}
+
+// parseNth parses an+b
+func parseNth(s string) (a int, b int, err error) {
+	s = strings.ToLower(strings.ReplaceAll(s, " ", ""))
+
+	switch s {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+
+	i := strings.IndexByte(s, 'n')
+	if i < 0 {
+		b, err = strconv.Atoi(s)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid nth %q", s)
+		}
+		return 0, b, nil
+	}
+
+	switch as := s[0:i]; as {
+	case "", "+":
+		a = 1
+	case "-":
+		a = -1
+	default:
+		if a, err = strconv.Atoi(as); err != nil {
+			return 0, 0, fmt.Errorf("invalid nth %q", s)
+		}
+	}
+
+	if bs := s[i+1:]; bs != "" {
+		if b, err = strconv.Atoi(bs); err != nil {
+			return 0, 0, fmt.Errorf("invalid nth %q", s)
+		}
+	}
+
+	return a, b, nil
+}
+
+func matchNth(a int, b int, idx int) bool {
+	if a == 0 {
+		return idx == b
+	}
+	n := idx - b
+	return n%a == 0 && n/a >= 0
+}
+
+// elementIndex returns 1-based index of e in its element siblings
+func elementIndex(e Element, ofType bool, fromEnd bool) int {
+	idx := 1
+
+	next := func(n Node) Node {
+		if fromEnd {
+			return n.NextSibling()
+		}
+		return n.PreviousSibling()
+	}
+
+	for s := next(e); s != nil; s = next(s) {
+		if s.NodeType() != ELEMENT_NODE {
+			continue
+		}
+		if ofType && !strings.EqualFold(s.NodeName(), e.NodeName()) {
+			continue
+		}
+		idx++
+	}
+
+	return idx
+}
+
+func parentElement(n Node) Element {
+	if p := n.ParentNode(); p != nil && p.NodeType() == ELEMENT_NODE {
+		return p.(Element)
+	}
+	return nil
+}
+
+func previousElementSibling(n Node) Element {
+	for s := n.PreviousSibling(); s != nil; s = s.PreviousSibling() {
+		if s.NodeType() == ELEMENT_NODE {
+			return s.(Element)
+		}
+	}
+	return nil
+}
+
+func hasAttr(e Element, name string) bool {
+	for _, n := range e.GetAttributeNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func attrValue(e Element, name string) string {
+	v := e.GetAttribute(name)
+	if v == nil {
+		return ""
+	}
+	return stringify(v)
+}
+
+func includesWord(s string, word string) bool {
+	if word == "" {
+		return false
+	}
+	for _, w := range strings.Fields(s) {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package dom
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSelector(t *testing.T) {
+	el := func(tagName string, attrs map[string]interface{}, children ...Element) Element {
+		e := Document.CreateElement(tagName)
+		for k, v := range attrs {
+			e.SetAttribute(k, v)
+		}
+		for i := range children {
+			e.AppendChild(children[i])
+		}
+		return e
+	}
+
+	root := el("div", map[string]interface{}{"id": "root"},
+		el("ul", map[string]interface{}{"class": "list main"},
+			el("li", map[string]interface{}{"id": "1", "data-k": "a-1"}),
+			el("li", map[string]interface{}{"id": "2", "class": []interface{}{"item", "active"}}),
+			el("li", map[string]interface{}{"id": "3", "lang": "en-US"}),
+			el("li", map[string]interface{}{"id": "4"}),
+		),
+		el("p", map[string]interface{}{"id": "5", "title": "Hello World"}),
+		el("span", map[string]interface{}{"id": "6"}),
+	)
+
+	body := Document.Body()
+	body.AppendChild(root)
+	defer body.RemoveChild(root)
+
+	ids := func(list ElementList) []string {
+		values := make([]string, 0)
+		list.Each(func(n Element) {
+			values = append(values, n.GetAttribute("id").(string))
+		})
+		return values
+	}
+
+	cases := []struct {
+		selector string
+		ids      []string
+	}{
+		{"li", []string{"1", "2", "3", "4"}},
+		{"#root > ul > li.active", []string{"2"}},
+		{".list.main li:nth-child(2n+1)", []string{"1", "3"}},
+		{"li:nth-last-child(1), li:first-child", []string{"1", "4"}},
+		{"li:not(:first-child):not(.item)", []string{"3", "4"}},
+		{"[data-k]", []string{"1"}},
+		{"[data-k|=a]", []string{"1"}},
+		{"[lang^='en']", []string{"3"}},
+		{"[title*=\"lo W\"]", []string{"5"}},
+		{"[title$=world i]", []string{"5"}},
+		{"ul + p", []string{"5"}},
+		{"ul ~ *", []string{"5", "6"}},
+		{"li:empty:nth-of-type(-n+2)", []string{"1", "2"}},
+		{"div span", []string{"6"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.selector, func(t *testing.T) {
+			NewWithT(t).Expect(ids(root.QuerySelectorAll(c.selector))).To(Equal(c.ids))
+		})
+	}
+
+	t.Run("should query from document", func(t *testing.T) {
+		NewWithT(t).Expect(Document.QuerySelector("#root")).To(Equal(root))
+		NewWithT(t).Expect(Document.QuerySelector("body > #root > p")).To(Equal(root.QuerySelector("p")))
+		NewWithT(t).Expect(Document.QuerySelector("#not-exists")).To(BeNil())
+	})
+
+	t.Run("should match and find closest", func(t *testing.T) {
+		li := root.QuerySelector("li.item")
+
+		NewWithT(t).Expect(li.Matches("ul > .active")).To(BeTrue())
+		NewWithT(t).Expect(li.Matches("p")).To(BeFalse())
+		NewWithT(t).Expect(li.Closest("div")).To(Equal(root))
+		NewWithT(t).Expect(li.Closest("li")).To(Equal(li))
+	})
+
+	t.Run("invalid selectors", func(t *testing.T) {
+		for _, s := range []string{"", "> a", "a >", "a[", "a::before", "a:unknown", "li:nth-child(x)", "a,", ",a", "a,,b", "a, ", "li:not(a,)"} {
+			_, err := ParseSelector(s)
+			NewWithT(t).Expect(err).NotTo(BeNil(), s)
+		}
+	})
+
+	t.Run("should keep commas in brackets and strings", func(t *testing.T) {
+		sel, err := ParseSelector(`li:not(.a, .b), [title="a,b"]`)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(sel).To(HaveLen(2))
+	})
+
+	t.Run("should bound cached selectors", func(t *testing.T) {
+		for i := 0; i < maxCachedSelectors*2; i++ {
+			_, err := ParseSelector(fmt.Sprintf(".item-%d", i))
+			NewWithT(t).Expect(err).To(BeNil())
+		}
+		NewWithT(t).Expect(selectors.Len()).To(Equal(maxCachedSelectors))
+	})
+}
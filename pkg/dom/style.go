@@ -0,0 +1,168 @@
+package dom
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSSStyleDeclaration https://developer.mozilla.org/en-US/docs/Web/API/CSSStyleDeclaration
+type CSSStyleDeclaration interface {
+	GetPropertyValue(name string) string
+	SetProperty(name string, value string)
+	RemoveProperty(name string)
+}
+
+var styleMapType = reflect.TypeOf(map[string]interface{}{})
+
+// StyleMapOf returns the style map of v, like map[string]interface{} or css.CSS
+func StyleMapOf(v interface{}) (map[string]interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Map && rv.Type().ConvertibleTo(styleMapType) {
+		return rv.Convert(styleMapType).Interface().(map[string]interface{}), true
+	}
+	return nil, false
+}
+
+// CSSPropertyName converts name in camel case to the css property name,
+// like fontSize to font-size, WebkitTransition or msTransform to -webkit-transition or -ms-transform.
+// custom properties like --color are kept.
+func CSSPropertyName(name string) string {
+	if strings.HasPrefix(name, "--") {
+		return name
+	}
+
+	if strings.HasPrefix(name, "ms") && len(name) > 2 && name[2] >= 'A' && name[2] <= 'Z' {
+		name = "M" + name[1:]
+	}
+
+	b := &strings.Builder{}
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c >= 'A' && c <= 'Z' {
+			b.WriteByte('-')
+			c += 'a' - 'A'
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// unitless properties, from https://github.com/facebook/react/blob/main/packages/react-dom-bindings/src/shared/isUnitlessNumber.js
+var unitlessProperties = map[string]bool{
+	"animation-iteration-count": true,
+	"aspect-ratio":              true,
+	"border-image-outset":       true,
+	"border-image-slice":        true,
+	"border-image-width":        true,
+	"box-flex":                  true,
+	"box-flex-group":            true,
+	"box-ordinal-group":         true,
+	"column-count":              true,
+	"columns":                   true,
+	"flex":                      true,
+	"flex-grow":                 true,
+	"flex-positive":             true,
+	"flex-shrink":               true,
+	"flex-negative":             true,
+	"flex-order":                true,
+	"grid-area":                 true,
+	"grid-row":                  true,
+	"grid-row-end":              true,
+	"grid-row-span":             true,
+	"grid-row-start":            true,
+	"grid-column":               true,
+	"grid-column-end":           true,
+	"grid-column-span":          true,
+	"grid-column-start":         true,
+	"font-weight":               true,
+	"line-clamp":                true,
+	"line-height":               true,
+	"opacity":                   true,
+	"order":                     true,
+	"orphans":                   true,
+	"scale":                     true,
+	"tab-size":                  true,
+	"widows":                    true,
+	"z-index":                   true,
+	"zoom":                      true,
+	"fill-opacity":              true,
+	"flood-opacity":             true,
+	"stop-opacity":              true,
+	"stroke-dasharray":          true,
+	"stroke-dashoffset":         true,
+	"stroke-miterlimit":         true,
+	"stroke-opacity":            true,
+	"stroke-width":              true,
+}
+
+// CSSValue converts v of the css property to string, numbers are converted with px unless the property is unitless.
+// returns false when the property should be removed, like nil, false or empty string.
+func CSSValue(property string, v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case nil:
+		return "", false
+	case bool:
+		return "", false
+	case string:
+		return x, x != ""
+	case fmt.Stringer:
+		s := x.String()
+		return s, s != ""
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return withUnit(property, fmt.Sprint(x)), true
+	case float32:
+		return withUnit(property, strconv.FormatFloat(float64(x), 'f', -1, 32)), true
+	case float64:
+		return withUnit(property, strconv.FormatFloat(x, 'f', -1, 64)), true
+	}
+
+	if _, ok := StyleMapOf(v); ok {
+		// nested rules could not be inline
+		return "", false
+	}
+
+	return fmt.Sprint(v), true
+}
+
+func withUnit(property string, n string) string {
+	if n == "0" || strings.HasPrefix(property, "--") || unitlessProperties[property] {
+		return n
+	}
+	return n + "px"
+}
+
+// StyleString serializes the style map sorted by property name
+func StyleString(style map[string]interface{}) string {
+	names := make([]string, 0, len(style))
+	values := make(map[string]string, len(style))
+
+	for k, v := range style {
+		name := CSSPropertyName(k)
+		if value, ok := CSSValue(name, v); ok {
+			names = append(names, name)
+			values[name] = value
+		}
+	}
+
+	sort.Strings(names)
+
+	b := &strings.Builder{}
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(values[name])
+		b.WriteByte(';')
+	}
+	return b.String()
+}
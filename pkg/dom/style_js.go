@@ -0,0 +1,24 @@
+//go:build js && wasm
+// +build js,wasm
+
+package dom
+
+type jsStyle struct {
+	JSValue
+}
+
+func (e *jsElement) Style() CSSStyleDeclaration {
+	return &jsStyle{JSValue: e.JSValue.Get("style")}
+}
+
+func (s *jsStyle) GetPropertyValue(name string) string {
+	return s.Call("getPropertyValue", name).String()
+}
+
+func (s *jsStyle) SetProperty(name string, value string) {
+	s.Call("setProperty", name, value)
+}
+
+func (s *jsStyle) RemoveProperty(name string) {
+	s.Call("removeProperty", name)
+}
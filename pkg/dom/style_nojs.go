@@ -0,0 +1,90 @@
+//go:build !js
+// +build !js
+
+package dom
+
+import (
+	"strings"
+)
+
+// elementStyle reads and writes declarations in the style attribute, like the browser does
+type elementStyle struct {
+	e *element
+}
+
+func (e *element) Style() CSSStyleDeclaration {
+	return &elementStyle{e: e}
+}
+
+type styleDeclaration struct {
+	name, value string
+}
+
+func (s *elementStyle) declarations() []styleDeclaration {
+	v := s.e.GetAttribute("style")
+	if v == nil {
+		return nil
+	}
+
+	list := make([]styleDeclaration, 0)
+
+	for _, d := range strings.Split(stringify(v), ";") {
+		i := strings.Index(d, ":")
+		if i < 0 {
+			continue
+		}
+		name, value := strings.TrimSpace(d[:i]), strings.TrimSpace(d[i+1:])
+		if name != "" && value != "" {
+			list = append(list, styleDeclaration{name: name, value: value})
+		}
+	}
+
+	return list
+}
+
+func (s *elementStyle) update(list []styleDeclaration) {
+	b := &strings.Builder{}
+	for _, d := range list {
+		b.WriteString(d.name)
+		b.WriteByte(':')
+		b.WriteString(d.value)
+		b.WriteByte(';')
+	}
+	s.e.SetAttribute("style", b.String())
+}
+
+func (s *elementStyle) GetPropertyValue(name string) string {
+	for _, d := range s.declarations() {
+		if d.name == name {
+			return d.value
+		}
+	}
+	return ""
+}
+
+func (s *elementStyle) SetProperty(name string, value string) {
+	if value == "" {
+		s.RemoveProperty(name)
+		return
+	}
+
+	list := s.declarations()
+	for i := range list {
+		if list[i].name == name {
+			list[i].value = value
+			s.update(list)
+			return
+		}
+	}
+	s.update(append(list, styleDeclaration{name: name, value: value}))
+}
+
+func (s *elementStyle) RemoveProperty(name string) {
+	list := s.declarations()
+	for i := range list {
+		if list[i].name == name {
+			s.update(append(list[:i], list[i+1:]...))
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package dom
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type namedStyle map[string]interface{}
+
+func TestStyle(t *testing.T) {
+	t.Run("should convert property names", func(t *testing.T) {
+		NewWithT(t).Expect(CSSPropertyName("fontSize")).To(Equal("font-size"))
+		NewWithT(t).Expect(CSSPropertyName("WebkitTransition")).To(Equal("-webkit-transition"))
+		NewWithT(t).Expect(CSSPropertyName("msTransform")).To(Equal("-ms-transform"))
+		NewWithT(t).Expect(CSSPropertyName("--mainColor")).To(Equal("--mainColor"))
+	})
+
+	t.Run("should convert values", func(t *testing.T) {
+		for _, c := range []struct {
+			property string
+			value    interface{}
+			expect   string
+			ok       bool
+		}{
+			{"width", 10, "10px", true},
+			{"width", 1.5, "1.5px", true},
+			{"width", 0, "0", true},
+			{"width", "50%", "50%", true},
+			{"opacity", 0.5, "0.5", true},
+			{"z-index", 2, "2", true},
+			{"--size", 2, "2", true},
+			{"width", nil, "", false},
+			{"width", "", "", false},
+			{"width", false, "", false},
+		} {
+			v, ok := CSSValue(c.property, c.value)
+			NewWithT(t).Expect(ok).To(Equal(c.ok))
+			NewWithT(t).Expect(v).To(Equal(c.expect))
+		}
+	})
+
+	t.Run("should update style attribute by properties", func(t *testing.T) {
+		div := Document.CreateElement("div")
+		div.SetAttribute("style", "color: red; width: 1px")
+
+		div.Style().SetProperty("width", "2px")
+		div.Style().SetProperty("height", "3px")
+		div.Style().RemoveProperty("color")
+
+		NewWithT(t).Expect(div.Style().GetPropertyValue("width")).To(Equal("2px"))
+		NewWithT(t).Expect(div.GetAttribute("style")).To(Equal("width:2px;height:3px;"))
+
+		div.Style().SetProperty("height", "")
+		NewWithT(t).Expect(div.GetAttribute("style")).To(Equal("width:2px;"))
+	})
+
+	t.Run("should write style map as attribute", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		WriteStartTag(buf, "div", map[string]interface{}{
+			"style": namedStyle{"zIndex": 1, "marginTop": 4, "color": nil},
+		})
+		NewWithT(t).Expect(buf.String()).To(Equal(`<div style="margin-top:4px;z-index:1;">`))
+	})
+}
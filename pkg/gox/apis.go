@@ -2,6 +2,7 @@ package gox
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-courier/gox/pkg/gox/internal"
 )
@@ -9,7 +10,6 @@ import (
 type VNode = internal.VNode
 type Elem = internal.Element
 type Key = internal.Key
-type Ref = internal.Ref
 type Component = internal.Component
 type Attrs = internal.Attrs
 
@@ -21,6 +21,41 @@ func Fragment(children ...interface{}) *VNode {
 	return H(internal.Fragment{})(children...)
 }
 
+// Boundary wraps children as a streaming boundary,
+// RenderToString flushes the html once the whole boundary is rendered.
+func Boundary(children ...interface{}) *VNode {
+	return H(internal.Boundary{})(children...)
+}
+
+type ErrorInfo = internal.ErrorInfo
+
+// ErrorBoundary catches panics from rendering and effects of children,
+// and renders fallback with the caught error instead.
+func ErrorBoundary(fallback func(info ErrorInfo) interface{}) func(children ...interface{}) *VNode {
+	return H(internal.ErrorBoundary{Fallback: fallback})
+}
+
+// Suspense renders fallback while children are reading pending resources by UseResource,
+// and renders children once all resolved.
+// Children are unmounted while fallback rendered, so their states will be reset.
+func Suspense(fallback interface{}) func(children ...interface{}) *VNode {
+	return H(internal.Suspense{Fallback: fallback})
+}
+
+// Memo wraps c, the rendered subtree will be reused
+// when c and children are shallow equal to the previous ones.
+func Memo(c Component) Component {
+	return internal.Memo{Component: c}
+}
+
+type ProfileInfo = internal.ProfileInfo
+
+// Profiler measures rendering of children,
+// onRender will be called after committed, when children mounted or updated.
+func Profiler(id string, onRender func(info ProfileInfo)) func(children ...interface{}) *VNode {
+	return H(internal.Profiler{ID: id, OnRender: onRender})
+}
+
 func JSX(c Component, children ...interface{}) *VNode {
 	return internal.JSX(c, children...)
 }
@@ -37,6 +72,39 @@ func Provider(c func(ctx context.Context) context.Context) func(children ...inte
 	}
 }
 
+// Context is the typed context created by CreateContext
+type Context[T any] struct {
+	defaultValue T
+}
+
+// CreateContext creates Context, UseContext returns defaultValue when no provider above.
+func CreateContext[T any](defaultValue T) *Context[T] {
+	return &Context[T]{defaultValue: defaultValue}
+}
+
+// Provide provides value to children,
+// consumers by UseContext will re-render when value changed, even if memoized.
+func (c *Context[T]) Provide(value T) func(children ...interface{}) *VNode {
+	return H(internal.ValueProvider{Key: c, Value: value})
+}
+
+// UseContext returns the value of the nearest provider of c, and subscribes its changes.
+func UseContext[T any](ctx context.Context, c *Context[T]) T {
+	vn := internal.VNodeFromContext(ctx)
+
+	p, _ := ctx.Value(c).(*internal.ProviderHook)
+
+	vn.Use(&internal.ContextHook{
+		Provider:      p,
+		OnStateChange: vn.UpdateByContext,
+	})
+
+	if p == nil {
+		return c.defaultValue
+	}
+	return valueAs[T](p.Get())
+}
+
 func UseEffect(ctx context.Context, setup func() func(), deps []interface{}) {
 	internal.VNodeFromContext(ctx).Use(&internal.EffectHook{
 		Setup: setup,
@@ -44,27 +112,61 @@ func UseEffect(ctx context.Context, setup func() func(), deps []interface{}) {
 	})
 }
 
-func UseMemo(ctx context.Context, setup func() interface{}, deps []interface{}) interface{} {
+// UseLayoutEffect is UseEffect, but setup runs synchronously after DOM mutations committed, before painting.
+func UseLayoutEffect(ctx context.Context, setup func() func(), deps []interface{}) {
+	internal.VNodeFromContext(ctx).Use(&internal.LayoutEffectHook{
+		EffectHook: internal.EffectHook{
+			Setup: setup,
+			Deps:  deps,
+		},
+	})
+}
+
+func UseMemo[T any](ctx context.Context, setup func() T, deps []interface{}) T {
 	h := internal.VNodeFromContext(ctx).Use(&internal.MemoHook{
-		Setup: setup,
-		Deps:  deps,
+		Setup: func() interface{} {
+			return setup()
+		},
+		Deps: deps,
 	}).(*internal.MemoHook)
 
-	return h.Memorised()
+	return valueAs[T](h.Memorised())
 }
 
-func UseRef(ctx context.Context, initialValue interface{}) *Ref {
+// Ref holds the mutable value between renders,
+// and receives the mounted node or component when passed as child.
+type Ref[T any] struct {
+	Current T
+}
+
+// SetCurrent sets Current, values which are not T will be treated as the zero value.
+func (r *Ref[T]) SetCurrent(v interface{}) {
+	r.Current = valueAs[T](v)
+}
 
+func UseRef[T any](ctx context.Context, initialValue T) *Ref[T] {
 	h := internal.VNodeFromContext(ctx).Use(&internal.RefHook{
-		Ref: Ref{
-			Current: initialValue,
+		Ref: internal.Ref{
+			Current: &Ref[T]{
+				Current: initialValue,
+			},
 		},
 	}).(*internal.RefHook)
 
-	return &h.Ref
+	return h.Ref.Current.(*Ref[T])
+}
+
+// SetStateFunc updates state by the previous state.
+type SetStateFunc[T any] func(update func(prev T) T)
+
+// Set replaces state with nextState
+func (setState SetStateFunc[T]) Set(nextState T) {
+	setState(func(prev T) T {
+		return nextState
+	})
 }
 
-func UseState(ctx context.Context, defaultState interface{}) (state interface{}, setState func(v interface{})) {
+func UseState[T any](ctx context.Context, defaultState T) (state T, setState SetStateFunc[T]) {
 	vn := internal.VNodeFromContext(ctx)
 
 	hook := vn.Use(&internal.StateHook{
@@ -72,5 +174,73 @@ func UseState(ctx context.Context, defaultState interface{}) (state interface{},
 		OnStateChange: vn.Update,
 	}).(*internal.StateHook)
 
-	return hook.State, hook.SetState
+	return valueAs[T](hook.State), func(update func(prev T) T) {
+		hook.SetState(func(prev interface{}) interface{} {
+			return update(valueAs[T](prev))
+		})
+	}
+}
+
+// UseReducer returns state and dispatch,
+// actions dispatched before next render are reduced together in the next render.
+func UseReducer[S any, A any](ctx context.Context, reducer func(state S, action A) S, initialState S) (state S, dispatch func(action A)) {
+	vn := internal.VNodeFromContext(ctx)
+
+	hook := vn.Use(&internal.ReducerHook{
+		State: initialState,
+		Reducer: func(state interface{}, action interface{}) interface{} {
+			return reducer(valueAs[S](state), valueAs[A](action))
+		},
+		OnStateChange: vn.Update,
+	}).(*internal.ReducerHook)
+
+	return valueAs[S](hook.State), func(action A) {
+		hook.Dispatch(action)
+	}
+}
+
+// UseResource returns the value fetched by fetch, once per key in the nearest Suspense.
+// While fetching, rendering is suspended and the nearest Suspense renders its fallback.
+// ctx of fetch is derived from ctx of the component, and canceled once no component reads the resource,
+// like the reading component unmounted, or the Suspense unmounted.
+// Resources fetched by RenderToString are scoped in the render, and released once it finished.
+func UseResource[T any](ctx context.Context, key string, fetch func(ctx context.Context) (T, error)) (T, error) {
+	h := internal.NearestSuspenseHook(internal.VNodeFromContext(ctx))
+	if h == nil {
+		panic(fmt.Errorf("UseResource(%q) must be used inside Suspense", key))
+	}
+
+	v, err := h.Resource(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return fetch(ctx)
+	}).Read()
+
+	return valueAs[T](v), err
+}
+
+// UseCallback returns the same fn until deps changed
+func UseCallback[F any](ctx context.Context, fn F, deps []interface{}) F {
+	return UseMemo(ctx, func() F {
+		return fn
+	}, deps)
+}
+
+// UseId returns id unique in the Root, which is generated at mount and stable between renders.
+// Ids generated by RenderToString are taken over by hydration.
+func UseId(ctx context.Context) string {
+	vn := internal.VNodeFromContext(ctx)
+
+	hook := vn.Use(&internal.IdHook{}).(*internal.IdHook)
+	if hook.ID == "" {
+		hook.ID = internal.IdGeneratorFromContext(ctx).NextId()
+	}
+
+	return hook.ID
+}
+
+// valueAs converts v to T, returns zero value of T when v is nil or not a T.
+func valueAs[T any](v interface{}) T {
+	if t, ok := v.(T); ok {
+		return t
+	}
+	return *new(T)
 }
@@ -0,0 +1,17 @@
+package internal
+
+import "context"
+
+// FlushBoundary marks components whose rendered subtree
+// should be flushed to the client as soon as it completes when streaming.
+type FlushBoundary interface {
+	FlushBoundary()
+}
+
+type Boundary struct{}
+
+func (Boundary) FlushBoundary() {}
+
+func (Boundary) Render(ctx context.Context, children ...interface{}) interface{} {
+	return JSX(Fragment{}, children...)
+}
@@ -19,7 +19,7 @@ func ShallowEqual(a interface{}, b interface{}) bool {
 
 			for k := range xa {
 				if xbv, ok := xb[k]; ok {
-					if xa[k] != xbv {
+					if !Identical(xa[k], xbv) {
 						return false
 					}
 				} else {
@@ -51,8 +51,61 @@ func ShallowEqual(a interface{}, b interface{}) bool {
 		return false
 	}
 
-	if reflect.TypeOf(a).Comparable() && reflect.TypeOf(b).Comparable() {
-		return a == b
+	// fields of structs like props of components are compared by Identical
+	return Identical(a, b)
+}
+
+// Identical compares a and b without panic on uncomparable values,
+// slices, maps and funcs are identical only when pointing to the same data,
+// structs, arrays and interfaces are compared by their elements.
+func Identical(a interface{}, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return identicalValue(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func identicalValue(va reflect.Value, vb reflect.Value) bool {
+	if va.Type() != vb.Type() {
+		return false
+	}
+
+	switch va.Kind() {
+	case reflect.Bool:
+		return va.Bool() == vb.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return va.Int() == vb.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return va.Uint() == vb.Uint()
+	case reflect.Float32, reflect.Float64:
+		return va.Float() == vb.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return va.Complex() == vb.Complex()
+	case reflect.String:
+		return va.String() == vb.String()
+	case reflect.Slice:
+		return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+	case reflect.Map, reflect.Func, reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return va.Pointer() == vb.Pointer()
+	case reflect.Interface:
+		if va.IsNil() || vb.IsNil() {
+			return va.IsNil() && vb.IsNil()
+		}
+		return identicalValue(va.Elem(), vb.Elem())
+	case reflect.Array:
+		for i := 0; i < va.Len(); i++ {
+			if !identicalValue(va.Index(i), vb.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < va.NumField(); i++ {
+			if !identicalValue(va.Field(i), vb.Field(i)) {
+				return false
+			}
+		}
+		return true
 	}
 
 	return false
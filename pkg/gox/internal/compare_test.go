@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+type props struct {
+	V        interface{}
+	List     []int
+	internal interface{}
+}
+
+func TestShallowEqual(t *testing.T) {
+	list := []int{1}
+	m := map[string]int{"a": 1}
+
+	cases := []struct {
+		name  string
+		a, b  interface{}
+		equal bool
+	}{
+		{"same values", props{V: 1, List: list}, props{V: 1, List: list}, true},
+		{"same slice in interface", props{V: list}, props{V: list}, true},
+		{"other slice in interface", props{V: []int{1}}, props{V: []int{1}}, false},
+		{"same map in unexported field", props{internal: m}, props{internal: m}, true},
+		{"other map in unexported field", props{internal: map[string]int{}}, props{internal: map[string]int{}}, false},
+		{"nested struct", props{V: props{V: list}}, props{V: props{V: list}}, true},
+		{"map values of slices", map[string]interface{}{"a": list}, map[string]interface{}{"a": list}, true},
+		{"map values of other slices", map[string]interface{}{"a": []int{1}}, map[string]interface{}{"a": []int{1}}, false},
+		{"list of props", []interface{}{props{V: list}}, []interface{}{props{V: list}}, true},
+		{"different types", props{V: 1}, 1, false},
+		{"nil", nil, nil, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gomega.NewWithT(t).Expect(ShallowEqual(c.a, c.b)).To(gomega.Equal(c.equal))
+		})
+	}
+}
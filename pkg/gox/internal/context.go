@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ValueProvider provides Value of the context Key to descendants,
+// and re-renders subscribed consumers when Value changed.
+type ValueProvider struct {
+	Key   interface{}
+	Value interface{}
+}
+
+func (p ValueProvider) GetChildContext(ctx context.Context) context.Context {
+	vn := VNodeFromContext(ctx)
+
+	hook := vn.Use(&ProviderHook{Value: p.Value}).(*ProviderHook)
+
+	return context.WithValue(ctx, p.Key, hook)
+}
+
+func (ValueProvider) Render(ctx context.Context, children ...interface{}) interface{} {
+	return JSX(Fragment{}, children...)
+}
+
+// ProviderHook holds the provided value, which is stable between renders of the provider.
+type ProviderHook struct {
+	mu          sync.Mutex
+	Value       interface{}
+	subscribers map[*ContextHook]struct{}
+}
+
+func (h *ProviderHook) String() string {
+	return fmt.Sprintf("Provider: %v", h.Get())
+}
+
+func (h *ProviderHook) Update(next Hook) {
+	n, ok := next.(*ProviderHook)
+	if !ok || n == h {
+		return
+	}
+
+	h.mu.Lock()
+	if Identical(n.Value, h.Value) {
+		h.mu.Unlock()
+		return
+	}
+	h.Value = n.Value
+	subscribers := make([]*ContextHook, 0, len(h.subscribers))
+	for s := range h.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	h.mu.Unlock()
+
+	// consumers re-rendered with the provider will drop the scheduled updates as stale.
+	for _, s := range subscribers {
+		s.notify()
+	}
+}
+
+// Get returns the provided value
+func (h *ProviderHook) Get() interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.Value
+}
+
+func (h *ProviderHook) subscribe(s *ContextHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers == nil {
+		h.subscribers = map[*ContextHook]struct{}{}
+	}
+	h.subscribers[s] = struct{}{}
+}
+
+func (h *ProviderHook) unsubscribe(s *ContextHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers, s)
+}
+
+// ContextHook subscribes the nearest provider of the consumer.
+type ContextHook struct {
+	Provider      *ProviderHook
+	OnStateChange func()
+
+	mu sync.Mutex
+}
+
+func (h *ContextHook) String() string {
+	if h.Provider == nil {
+		return "UseContext"
+	}
+	return fmt.Sprintf("UseContext: %v", h.Provider.Get())
+}
+
+func (h *ContextHook) Update(next Hook) {
+	n, ok := next.(*ContextHook)
+	if !ok {
+		return
+	}
+
+	if n == h {
+		// first used
+		if h.Provider != nil {
+			h.Provider.subscribe(h)
+		}
+		return
+	}
+
+	h.mu.Lock()
+	// context may change, should bind the latest callback
+	h.OnStateChange = n.OnStateChange
+	h.mu.Unlock()
+
+	if n.Provider != h.Provider {
+		// moved under another provider
+		if h.Provider != nil {
+			h.Provider.unsubscribe(h)
+		}
+		h.Provider = n.Provider
+		if h.Provider != nil {
+			h.Provider.subscribe(h)
+		}
+	}
+}
+
+func (h *ContextHook) notify() {
+	h.mu.Lock()
+	onStateChange := h.OnStateChange
+	h.mu.Unlock()
+
+	if onStateChange != nil {
+		onStateChange()
+	}
+}
+
+func (h *ContextHook) Destroy() {
+	if h.Provider != nil {
+		h.Provider.unsubscribe(h)
+	}
+}
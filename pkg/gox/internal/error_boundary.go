@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ErrorInfo describes the panic caught by ErrorBoundary
+type ErrorInfo struct {
+	// Err is the recovered value, non-error values are wrapped
+	Err error
+	// ComponentStack lists components from the panicked one up to the ErrorBoundary
+	ComponentStack string
+	// Reset clears the caught error and re-renders the children
+	Reset func()
+}
+
+// ErrorBoundary catches panics from rendering and effects of descendants,
+// and renders Fallback instead of children.
+type ErrorBoundary struct {
+	Fallback func(info ErrorInfo) interface{}
+}
+
+func (b ErrorBoundary) Render(ctx context.Context, children ...interface{}) interface{} {
+	vn := VNodeFromContext(ctx)
+
+	hook := vn.Use(&ErrorBoundaryHook{OnStateChange: vn.Update}).(*ErrorBoundaryHook)
+
+	if info := hook.Caught(); info != nil {
+		if b.Fallback == nil {
+			return nil
+		}
+		return b.Fallback(*info)
+	}
+
+	return JSX(Fragment{}, children...)
+}
+
+// ErrorBoundaryHook holds the caught error of ErrorBoundary
+type ErrorBoundaryHook struct {
+	OnStateChange func()
+
+	mu     sync.Mutex
+	caught *ErrorInfo
+}
+
+func (h *ErrorBoundaryHook) Update(next Hook) {
+	if n, ok := next.(*ErrorBoundaryHook); ok {
+		h.mu.Lock()
+		h.OnStateChange = n.OnStateChange
+		h.mu.Unlock()
+	}
+}
+
+func (h *ErrorBoundaryHook) String() string {
+	return fmt.Sprintf("ErrorBoundary: %v", h.Caught())
+}
+
+func (h *ErrorBoundaryHook) Caught() *ErrorInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.caught
+}
+
+// Catch stores the error, which will be passed to Fallback in the next render.
+func (h *ErrorBoundaryHook) Catch(err error, componentStack string) {
+	h.mu.Lock()
+	h.caught = &ErrorInfo{
+		Err:            err,
+		ComponentStack: componentStack,
+		Reset:          h.reset,
+	}
+	h.mu.Unlock()
+}
+
+func (h *ErrorBoundaryHook) reset() {
+	h.mu.Lock()
+	h.caught = nil
+	onStateChange := h.OnStateChange
+	h.mu.Unlock()
+
+	if onStateChange != nil {
+		onStateChange()
+	}
+}
+
+// ErrorBoundaryHookOf returns the hook of ErrorBoundary VNode,
+// nil when v is not an ErrorBoundary or not rendered.
+func ErrorBoundaryHookOf(v *VNode) *ErrorBoundaryHook {
+	if _, ok := v.Type.(ErrorBoundary); !ok {
+		return nil
+	}
+	if len(v.usedHooks) > 0 {
+		if h, ok := v.usedHooks[0].(*ErrorBoundaryHook); ok {
+			return h
+		}
+	}
+	return nil
+}
@@ -4,10 +4,16 @@ type Hook interface {
 	Update(next Hook)
 }
 
+// HookCanCommit commits after painted
 type HookCanCommit interface {
 	Commit()
 }
 
+// HookCanCommitLayout commits after DOM mutations committed, before painting
+type HookCanCommitLayout interface {
+	CommitLayout()
+}
+
 type HookCanDestroy interface {
 	Destroy()
 }
@@ -45,6 +51,14 @@ func (hs *hooks) commit() {
 	}
 }
 
+func (hs *hooks) commitLayout() {
+	for i := range hs.usedHooks {
+		if hc, ok := hs.usedHooks[i].(HookCanCommitLayout); ok {
+			hc.CommitLayout()
+		}
+	}
+}
+
 func (hs *hooks) destroy() {
 	for i := range hs.usedHooks {
 		if hc, ok := hs.usedHooks[i].(HookCanDestroy); ok {
@@ -18,6 +18,7 @@ func (h *EffectHook) String() string {
 func (h *EffectHook) Destroy() {
 	if h.cleanup != nil {
 		h.cleanup()
+		h.cleanup = nil
 	}
 	h.commit = nil
 }
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// IdHook holds the id generated at first render
+type IdHook struct {
+	ID string
+}
+
+func (h *IdHook) Update(next Hook) {
+}
+
+func (h *IdHook) String() string {
+	return fmt.Sprintf("UseId: %s", h.ID)
+}
+
+// IdGenerator generates ids for UseId
+type IdGenerator interface {
+	NextId() string
+}
+
+// IdCounter generates ids by Prefix and an increasing count
+type IdCounter struct {
+	Prefix string
+	n      uint32
+}
+
+func (c *IdCounter) NextId() string {
+	return c.Prefix + strconv.FormatUint(uint64(atomic.AddUint32(&c.n, 1)), 10)
+}
+
+// IdSequence takes Ids in order first, then appends the ones generated by Generator,
+// to reuse the ids generated on server side, or to record the ids of a component.
+type IdSequence struct {
+	Ids       []string
+	Generator IdGenerator
+	i         int
+}
+
+func (s *IdSequence) NextId() string {
+	if s.i < len(s.Ids) {
+		id := s.Ids[s.i]
+		s.i++
+		return id
+	}
+	id := s.Generator.NextId()
+	s.Ids = append(s.Ids, id)
+	s.i++
+	return id
+}
+
+// ids for components rendered without a Root
+var defaultIds = &IdCounter{Prefix: "gox-"}
+
+type contextKeyIdGenerator struct{}
+
+func ContextWithIdGenerator(ctx context.Context, g IdGenerator) context.Context {
+	return context.WithValue(ctx, contextKeyIdGenerator{}, g)
+}
+
+func IdGeneratorFromContext(ctx context.Context) IdGenerator {
+	if g, ok := ctx.Value(contextKeyIdGenerator{}).(IdGenerator); ok {
+		return g
+	}
+	return defaultIds
+}
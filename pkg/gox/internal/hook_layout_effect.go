@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"fmt"
+)
+
+// LayoutEffectHook is EffectHook, but commits right after all DOM mutations committed before paint.
+type LayoutEffectHook struct {
+	EffectHook
+}
+
+func (h *LayoutEffectHook) String() string {
+	return fmt.Sprintf("UseLayoutEffect: %v", h.Deps)
+}
+
+// Commit do nothing, which is for passive effects.
+func (h *LayoutEffectHook) Commit() {
+}
+
+func (h *LayoutEffectHook) CommitLayout() {
+	h.EffectHook.Commit()
+}
+
+func (h *LayoutEffectHook) Update(next Hook) {
+	h.EffectHook.Update(&next.(*LayoutEffectHook).EffectHook)
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ReducerHook queues dispatched actions, and reduces them all in the next render.
+type ReducerHook struct {
+	State         interface{}
+	Reducer       func(state interface{}, action interface{}) interface{}
+	OnStateChange func()
+
+	mu      sync.Mutex
+	pending []interface{}
+}
+
+func (h *ReducerHook) String() string {
+	return fmt.Sprintf("UseReducer: %v", h.State)
+}
+
+func (h *ReducerHook) Update(next Hook) {
+	h.mu.Lock()
+	if n, ok := next.(*ReducerHook); ok {
+		// context may change, should bind the latest callback and reducer
+		h.OnStateChange = n.OnStateChange
+		h.Reducer = n.Reducer
+	}
+	pending := h.pending
+	h.pending = nil
+	h.mu.Unlock()
+
+	for _, action := range pending {
+		h.State = h.Reducer(h.State, action)
+	}
+}
+
+// Dispatch queues the action, only the first action before next render triggers the update.
+func (h *ReducerHook) Dispatch(action interface{}) {
+	h.mu.Lock()
+	h.pending = append(h.pending, action)
+	first := len(h.pending) == 1
+	onStateChange := h.OnStateChange
+	h.mu.Unlock()
+
+	if first {
+		onStateChange()
+	}
+}
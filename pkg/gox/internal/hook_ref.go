@@ -11,6 +11,11 @@ type RefHook struct {
 func (RefHook) Update(next Hook) {
 }
 
+// Destroy releases the referenced value
+func (s *RefHook) Destroy() {
+	s.Ref.Current = nil
+}
+
 func (s *RefHook) String() string {
 	return fmt.Sprintf("UseRef: %v", s.Ref.Current)
 }
@@ -2,35 +2,70 @@ package internal
 
 import (
 	"fmt"
+	"sync"
 )
 
+// StateHook queues state updates, which could be set from any goroutine,
+// and applies them in the next render.
 type StateHook struct {
 	State         interface{}
 	OnStateChange func()
+
+	mu      sync.Mutex
+	pending []func(prev interface{}) interface{}
 }
 
 func (s *StateHook) Update(next Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if n, ok := next.(*StateHook); ok {
 		// context may change, should bind the latest callback
 		s.OnStateChange = n.OnStateChange
 	}
+
+	for _, update := range s.pending {
+		s.State = update(s.State)
+	}
+	s.pending = nil
 }
 
 func (s *StateHook) String() string {
 	return fmt.Sprintf("UseState: %v", s.State)
 }
 
+// SetState queues the next state or the func to compute next state by the previous state.
+// when no update pending, the next state is computed at once, and dropped when not changed.
+// the func is called only once.
 func (s *StateHook) SetState(nextStateOrValueFunc interface{}) {
-	switch x := nextStateOrValueFunc.(type) {
-	case func(v interface{}) interface{}:
-		if nextState := x(s.State); nextState != s.State {
-			s.State = nextState
-			s.OnStateChange()
+	update, ok := nextStateOrValueFunc.(func(v interface{}) interface{})
+	if !ok {
+		update = func(v interface{}) interface{} {
+			return nextStateOrValueFunc
+		}
+	}
+
+	s.mu.Lock()
+
+	if len(s.pending) == 0 {
+		next := update(s.State)
+		if Identical(next, s.State) {
+			s.mu.Unlock()
+			return
 		}
-	default:
-		if x != s.State {
-			s.State = x
-			s.OnStateChange()
+		// state is only changed by pending updates, so the computed one is kept.
+		update = func(v interface{}) interface{} {
+			return next
 		}
 	}
+
+	s.pending = append(s.pending, update)
+	first := len(s.pending) == 1
+	onStateChange := s.OnStateChange
+
+	s.mu.Unlock()
+
+	if first {
+		onStateChange()
+	}
 }
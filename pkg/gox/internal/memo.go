@@ -0,0 +1,33 @@
+package internal
+
+import "context"
+
+// ShouldUpdate could be implemented by components to skip re-rendering.
+// When ShouldUpdate returns false and children are shallow equal,
+// the previous rendered subtree will be reused.
+type ShouldUpdate interface {
+	ShouldUpdate(prev Component) bool
+}
+
+// Memo wraps Component, which only re-renders when the component value or children changed.
+type Memo struct {
+	Component Component
+}
+
+func (m Memo) ShouldUpdate(prev Component) bool {
+	if p, ok := prev.(Memo); ok {
+		return !ShallowEqual(m.Component, p.Component)
+	}
+	return true
+}
+
+func (m Memo) GetChildContext(ctx context.Context) context.Context {
+	if cp, ok := m.Component.(ContextProvider); ok {
+		return cp.GetChildContext(ctx)
+	}
+	return ctx
+}
+
+func (m Memo) Render(ctx context.Context, children ...interface{}) interface{} {
+	return m.Component.Render(ctx, children...)
+}
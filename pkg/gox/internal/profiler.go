@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// ProfileInfo describes a render of the children of Profiler
+type ProfileInfo struct {
+	// ID of the Profiler
+	ID string
+	// Phase is "mount" or "update"
+	Phase string
+	// StartTime of the render
+	StartTime time.Time
+	// ActualDuration of rendering the Profiler, or the updated descendants
+	ActualDuration time.Duration
+}
+
+// Profiler reports ProfileInfo to OnRender, after children mounted or updated.
+type Profiler struct {
+	ID       string
+	OnRender func(info ProfileInfo)
+}
+
+func (Profiler) Render(ctx context.Context, children ...interface{}) interface{} {
+	return JSX(Fragment{}, children...)
+}
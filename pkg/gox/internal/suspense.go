@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Suspended is panicked when rendering reads a pending Resource,
+// the nearest Suspense renders the fallback until Done closed.
+type Suspended struct {
+	Key  string
+	Done <-chan struct{}
+}
+
+func (s *Suspended) Error() string {
+	return fmt.Sprintf("suspended by resource %q", s.Key)
+}
+
+// Resource is the async value fetched once
+type Resource struct {
+	key   string
+	done  chan struct{}
+	value interface{}
+	err   error
+	// the attempt of Suspense which read it last
+	attempt int
+	cancel  context.CancelFunc
+}
+
+func (r *Resource) pending() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Read returns the value once fetched, or panics *Suspended when pending.
+func (r *Resource) Read() (interface{}, error) {
+	select {
+	case <-r.done:
+		return r.value, r.err
+	default:
+		panic(&Suspended{Key: r.key, Done: r.done})
+	}
+}
+
+// Suspense renders Fallback while descendants are reading pending resources.
+type Suspense struct {
+	Fallback interface{}
+}
+
+func (s Suspense) Render(ctx context.Context, children ...interface{}) interface{} {
+	vn := VNodeFromContext(ctx)
+
+	hook := vn.Use(&SuspenseHook{OnStateChange: vn.Update}).(*SuspenseHook)
+
+	if !hook.attempt() {
+		return s.Fallback
+	}
+
+	return JSX(Fragment{}, children...)
+}
+
+// SuspenseHook caches resources of descendants, and tracks the pending ones.
+type SuspenseHook struct {
+	OnStateChange func()
+
+	mu        sync.Mutex
+	resources map[string]*Resource
+	waiting   int
+	done      chan struct{}
+	// fallback is rendered once right after suspended
+	fallback bool
+	// counts the renders of children
+	attempts int
+}
+
+func (h *SuspenseHook) Update(next Hook) {
+	if n, ok := next.(*SuspenseHook); ok {
+		h.mu.Lock()
+		h.OnStateChange = n.OnStateChange
+		h.mu.Unlock()
+	}
+}
+
+func (h *SuspenseHook) String() string {
+	return fmt.Sprintf("Suspense: %v", h.Suspended())
+}
+
+// Resource returns the cached resource of key,
+// or starts fetching with ctx of the reading component.
+// Fetching is canceled once the children of Suspense rendered without reading it,
+// like the reading component unmounted, or the Suspense destroyed.
+func (h *SuspenseHook) Resource(ctx context.Context, key string, fetch func(ctx context.Context) (interface{}, error)) *Resource {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r, ok := h.resources[key]; ok {
+		r.attempt = h.attempts
+		return r
+	}
+
+	if h.resources == nil {
+		h.resources = map[string]*Resource{}
+	}
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+
+	r := &Resource{key: key, done: make(chan struct{}), attempt: h.attempts, cancel: cancel}
+	h.resources[key] = r
+
+	go func() {
+		defer close(r.done)
+		r.value, r.err = fetch(fetchCtx)
+	}()
+
+	return r
+}
+
+// attempt returns false when the fallback should be rendered,
+// or counts the attempt to render children.
+func (h *SuspenseHook) attempt() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.fallback {
+		h.fallback = false
+		return false
+	}
+	h.attempts++
+	return true
+}
+
+// Sweep cancels the pending resources not read in the last attempt to render children,
+// should be called once the Suspense rendered.
+func (h *SuspenseHook) Sweep() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, r := range h.resources {
+		if r.attempt < h.attempts && r.pending() {
+			r.cancel()
+			// fetched again when read later
+			delete(h.resources, key)
+		}
+	}
+}
+
+// Suspended returns true when waiting for resources
+func (h *SuspenseHook) Suspended() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.waiting > 0
+}
+
+// Suspend makes Suspense render fallback until done closed, then re-render children.
+func (h *SuspenseHook) Suspend(done <-chan struct{}) {
+	h.mu.Lock()
+	h.waiting++
+	h.fallback = true
+	if h.done == nil {
+		h.done = make(chan struct{})
+	}
+	destroyed := h.done
+	h.mu.Unlock()
+
+	go func() {
+		select {
+		case <-done:
+		case <-destroyed:
+			return
+		}
+
+		h.mu.Lock()
+		h.waiting--
+		onStateChange := h.OnStateChange
+		h.mu.Unlock()
+
+		if onStateChange != nil {
+			onStateChange()
+		}
+	}()
+}
+
+// Destroy cancels all fetching
+func (h *SuspenseHook) Destroy() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, r := range h.resources {
+		r.cancel()
+	}
+
+	if h.done == nil {
+		h.done = make(chan struct{})
+	}
+	select {
+	case <-h.done:
+	default:
+		close(h.done)
+	}
+}
+
+// SuspenseHookOf returns the hook of Suspense VNode,
+// nil when v is not a Suspense or not rendered.
+func SuspenseHookOf(v *VNode) *SuspenseHook {
+	if _, ok := v.Type.(Suspense); !ok {
+		return nil
+	}
+	if len(v.usedHooks) > 0 {
+		if h, ok := v.usedHooks[0].(*SuspenseHook); ok {
+			return h
+		}
+	}
+	return nil
+}
+
+// NearestSuspenseHook returns the hook of the nearest Suspense of v
+func NearestSuspenseHook(v *VNode) *SuspenseHook {
+	for vn := v.Parent; vn != nil; vn = vn.Parent {
+		if h := SuspenseHookOf(vn); h != nil {
+			return h
+		}
+	}
+	return nil
+}
@@ -23,10 +23,19 @@ func VNodeFromContext(ctx context.Context) *VNode {
 
 type Key string
 
+// RefObject could be passed as child of JSX, to receive the mounted node
+type RefObject interface {
+	SetCurrent(v interface{})
+}
+
 type Ref struct {
 	Current interface{}
 }
 
+func (r *Ref) SetCurrent(v interface{}) {
+	r.Current = v
+}
+
 func JSX(tpe Component, children ...interface{}) *VNode {
 	v := &VNode{
 		Type:  tpe,
@@ -66,7 +75,7 @@ type VNode struct {
 	Type  Component
 	Key   Key
 	Attrs Attrs
-	Ref   *Ref
+	Ref   RefObject
 
 	InputChildren []interface{}
 	Children      []interface{}
@@ -75,7 +84,9 @@ type VNode struct {
 
 	IsRoot bool
 	Node   dom.Element
-	update func(vn *VNode)
+	update func(vn *VNode, reason UpdateReason)
+	// replaced by the next rendered VNode or destroyed
+	stale bool
 	hooks
 }
 
@@ -83,7 +94,34 @@ func (v *VNode) String() string {
 	return fmt.Sprintf("%#v", v.Type)
 }
 
+// Compare makes VNodes shallow equal when they are the same one or the same text.
+func (v *VNode) Compare(x interface{}) int {
+	if vn, ok := x.(*VNode); ok {
+		if vn == v {
+			return 0
+		}
+		if t, ok := v.Type.(Text); ok && vn.Type == t && vn.Key == v.Key {
+			return 0
+		}
+	}
+	return 1
+}
+
 func SameComponent(type1 Component, type2 Component) bool {
+	if p1, ok := type1.(ValueProvider); ok {
+		if p2, ok := type2.(ValueProvider); ok {
+			return p1.Key == p2.Key
+		}
+		return false
+	}
+
+	if m1, ok := type1.(Memo); ok {
+		if m2, ok := type2.(Memo); ok {
+			return SameComponent(m1.Component, m2.Component)
+		}
+		return false
+	}
+
 	if typeE1, ok := type1.(Element); ok {
 		if typeE2, ok := type2.(Element); ok {
 			return typeE1 == typeE2
@@ -111,27 +149,69 @@ func (v *VNode) MountedNode() dom.Element {
 	return mounted
 }
 
+// HookIndex returns the index of the next used hook
+func (v *VNode) HookIndex() int {
+	return v.hookUseIdx
+}
+
+// Hooks returns hooks used by v in order
+func (v *VNode) Hooks() []Hook {
+	return v.usedHooks
+}
+
 func (v *VNode) Use(hook Hook) Hook {
 	return v.hooks.use(hook)
 }
 
-func (v *VNode) OnUpdate(fn func(vn *VNode)) {
+// UpdateReason tells why a VNode updated by itself
+type UpdateReason int
+
+const (
+	// UpdateByState when states of hooks changed
+	UpdateByState UpdateReason = iota
+	// UpdateByContext when values of subscribed contexts changed
+	UpdateByContext
+)
+
+func (v *VNode) OnUpdate(fn func(vn *VNode, reason UpdateReason)) {
 	v.update = fn
 }
 
+// Update requests re-rendering v, as states changed
 func (v *VNode) Update() {
-	v.update(v)
+	v.UpdateBy(UpdateByState)
+}
+
+// UpdateByContext requests re-rendering v, as subscribed context changed
+func (v *VNode) UpdateByContext() {
+	v.UpdateBy(UpdateByContext)
+}
+
+func (v *VNode) UpdateBy(reason UpdateReason) {
+	if v.update != nil {
+		v.update(v, reason)
+	}
+}
+
+// Stale returns true when v is replaced by the next rendered VNode or destroyed,
+// updates of stale VNode should be dropped.
+func (v *VNode) Stale() bool {
+	return v.stale
 }
 
 func (v *VNode) WillRender(oldVNode *VNode) {
+	// VNode could be rendered again after destroyed, when its parent re-rendered with same children
+	v.stale = false
 	if oldVNode != nil {
 		v.hooks = oldVNode.hooks
+		oldVNode.stale = true
 	}
 	v.hooks.hookInit()
 }
 
 func (v *VNode) WillMount(oldVNode *VNode) {
 	if oldVNode != nil {
+		oldVNode.stale = true
 		v.IsRoot = oldVNode.IsRoot
 		v.Node = oldVNode.Node
 	}
@@ -140,20 +220,27 @@ func (v *VNode) WillMount(oldVNode *VNode) {
 func (v *VNode) DidMount() {
 	if v.Ref != nil {
 		if v.Node != nil {
-			v.Ref.Current = v.Node
+			v.Ref.SetCurrent(v.Node)
 		} else {
 			// in golang Type is also Component instance.
-			v.Ref.Current = v.Type
+			v.Ref.SetCurrent(v.Type)
 		}
 	}
+	v.hooks.commitLayout()
+}
+
+// DidPaint commits passive effects
+func (v *VNode) DidPaint() {
 	v.hooks.commit()
 }
 
 func (v *VNode) Destroy() error {
 	if v.Ref != nil {
-		v.Ref.Current = nil
+		v.Ref.SetCurrent(nil)
 	}
 	v.Node = nil
+	v.stale = true
 	v.hooks.destroy()
+	v.hooks = hooks{}
 	return nil
 }
@@ -0,0 +1,247 @@
+package renderer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-courier/gox/pkg/gox/internal"
+
+	. "github.com/go-courier/gox/pkg/gox"
+)
+
+// RenderPanic is the panic recovered from rendering or effects,
+// re-panicked when no ErrorBoundary or Suspense could catch it.
+type RenderPanic struct {
+	// Value recovered
+	Value interface{}
+	// Origin is the VNode rendering when panicked
+	Origin *VNode
+	err    error
+}
+
+func (p *RenderPanic) Error() string {
+	return fmt.Sprintf("renderer: panic in %s: %v", typeName(p.Origin), p.err)
+}
+
+func (p *RenderPanic) Unwrap() error {
+	return p.err
+}
+
+func newRenderPanic(e interface{}, origin *VNode) *RenderPanic {
+	if p, ok := e.(*RenderPanic); ok {
+		return p
+	}
+
+	p := &RenderPanic{Value: e, Origin: origin}
+
+	if err, ok := e.(error); ok {
+		p.err = err
+	} else {
+		p.err = fmt.Errorf("%v", e)
+	}
+
+	return p
+}
+
+// vnodeStack tracks VNodes being rendered, the top one is where panicked.
+type vnodeStack []*VNode
+
+func (s *vnodeStack) push(vnode *VNode) {
+	*s = append(*s, vnode)
+}
+
+func (s *vnodeStack) pop() {
+	if n := len(*s); n > 0 {
+		*s = (*s)[:n-1]
+	}
+}
+
+// recoverRender runs fn, the panic will be recovered with the queued commits rolled back.
+func (r *Root) recoverRender(vnode *VNode, fn func()) (p *RenderPanic) {
+	depth := len(r.stack)
+	mark := r.cq.mark()
+
+	defer func() {
+		if e := recover(); e != nil {
+			origin := vnode
+			if len(r.stack) > depth {
+				origin = r.stack[len(r.stack)-1]
+			}
+			p = newRenderPanic(e, origin)
+
+			r.stack = r.stack[:depth]
+			r.cq.rollback(mark)
+		}
+	}()
+
+	fn()
+
+	return nil
+}
+
+// renderBoundary renders the ErrorBoundary or Suspense,
+// once descendants panicked and the boundary caught it, renders again with the fallback.
+func (r *Root) renderBoundary(vnode *VNode, oldVNode *VNode, render func(oldVNode *VNode)) {
+	var oldChildren []interface{}
+	if oldVNode != nil {
+		// patching may change the children list
+		oldChildren = append(oldChildren, oldVNode.Children...)
+	}
+
+	defer func() {
+		// hooks are taken over by vnode in rendering
+		if h := internal.SuspenseHookOf(vnode); h != nil {
+			h.Sweep()
+		}
+	}()
+
+	p := r.recoverRender(vnode, func() {
+		render(oldVNode)
+	})
+
+	if p == nil {
+		return
+	}
+
+	if oldVNode != nil {
+		oldVNode.Children = oldChildren
+	}
+
+	if !catchAt(vnode, p) {
+		// not caught, or the fallback panicked, leave it to the upper boundary
+		panic(p)
+	}
+
+	render(oldVNode)
+}
+
+func isBoundary(vnode *VNode) bool {
+	switch vnode.Type.(type) {
+	case internal.ErrorBoundary, internal.Suspense:
+		return true
+	}
+	return false
+}
+
+// catchAt passes p to the boundary vnode, returns false when vnode could not catch it.
+// Suspense only catches *internal.Suspended, and ErrorBoundary catches others.
+func catchAt(vnode *VNode, p *RenderPanic) bool {
+	suspended := &internal.Suspended{}
+	isSuspended := errors.As(p.err, &suspended)
+
+	switch vnode.Type.(type) {
+	case internal.ErrorBoundary:
+		if h := internal.ErrorBoundaryHookOf(vnode); h != nil && !isSuspended && h.Caught() == nil {
+			h.Catch(p.err, componentStack(p.Origin, vnode))
+			return true
+		}
+	case internal.Suspense:
+		// Suspense renders children again once re-rendered, so catches even already suspended
+		if h := internal.SuspenseHookOf(vnode); h != nil && isSuspended {
+			h.Suspend(suspended.Done)
+			return true
+		}
+	}
+	return false
+}
+
+// renderUpdate renders the scheduled update of vnode,
+// panics are routed to the nearest boundary of vnode.
+func (r *Root) renderUpdate(vnode *VNode, rendered *VNode, render func(oldVNode *VNode)) {
+	children := append([]interface{}(nil), rendered.Children...)
+
+	// nodes of the component are placed in the rendered tree
+	r.before = nextHostNode(vnode)
+	defer func() {
+		r.before = nil
+	}()
+
+	p := r.recoverRender(vnode, func() {
+		render(rendered)
+	})
+
+	if p == nil {
+		return
+	}
+
+	// restore to the last rendered, the commits are rolled back
+	vnode.Children = children
+	rendered.Children = children
+
+	if !r.catchByBoundary(vnode.Parent, p) {
+		panic(p)
+	}
+}
+
+// runEffects runs effects of vnode, panics are routed to the nearest boundary of vnode.
+func (r *Root) runEffects(vnode *VNode, fn func()) {
+	defer func() {
+		if e := recover(); e != nil {
+			p := newRenderPanic(e, vnode)
+			if !r.catchByBoundary(vnode.Parent, p) {
+				panic(p)
+			}
+		}
+	}()
+
+	fn()
+}
+
+// catchByBoundary passes p to the nearest boundary from vnode, which will render the fallback in next update.
+func (r *Root) catchByBoundary(vnode *VNode, p *RenderPanic) bool {
+	for v := vnode; v != nil; v = v.Parent {
+		if catchAt(v, p) {
+			v.Update()
+			return true
+		}
+	}
+	return false
+}
+
+// componentStack lists VNodes from the origin up to the boundary, like
+//
+//	in Widget
+//	in div
+//	in ErrorBoundary
+func componentStack(origin *VNode, boundary *VNode) string {
+	b := &strings.Builder{}
+
+	for v := origin; v != nil; v = v.Parent {
+		switch v.Type.(type) {
+		case internal.Fragment, internal.Text:
+		default:
+			b.WriteString("\n    in ")
+			b.WriteString(typeName(v))
+		}
+		if v == boundary {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+func typeName(vnode *VNode) string {
+	if vnode == nil {
+		return "<nil>"
+	}
+
+	switch x := vnode.Type.(type) {
+	case internal.Text:
+		return "#text"
+	case internal.Element:
+		return string(x)
+	case internal.Memo:
+		return "Memo(" + typeName(&VNode{Type: x.Component}) + ")"
+	case internal.Profiler:
+		return "Profiler(" + x.ID + ")"
+	default:
+		t := reflect.TypeOf(x)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		return t.Name()
+	}
+}
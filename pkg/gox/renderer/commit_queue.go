@@ -4,82 +4,319 @@ import (
 	"sync"
 	"time"
 
-	"github.com/go-courier/gox/pkg/browser"
+	. "github.com/go-courier/gox/pkg/gox"
 )
 
+// CommitPhase of a commit, phases of each commit run in order,
+// and all phases of a commit run before the next commit.
+type CommitPhase int
+
+const (
+	// MutationPhase applies DOM mutations
+	MutationPhase CommitPhase = iota
+	// LayoutPhase runs layout effects, after all DOM mutations committed
+	LayoutPhase
+	// PassivePhase runs passive effects, after painted
+	PassivePhase
+)
+
+// CommitMetrics counts queued functions of commits
+type CommitMetrics struct {
+	Mutations      int
+	LayoutEffects  int
+	PassiveEffects int
+}
+
+func (m CommitMetrics) add(o CommitMetrics) CommitMetrics {
+	return CommitMetrics{
+		Mutations:      m.Mutations + o.Mutations,
+		LayoutEffects:  m.LayoutEffects + o.LayoutEffects,
+		PassiveEffects: m.PassiveEffects + o.PassiveEffects,
+	}
+}
+
+// Metrics of commits of a Root
+type Metrics struct {
+	// Commits is the count of commits, each commit is applied in one frame
+	Commits int
+	// Last commit
+	Last CommitMetrics
+	// Max of all commits
+	Max CommitMetrics
+	// Total of all commits
+	Total CommitMetrics
+}
+
+// frame is a commit waiting to run
+type frame struct {
+	phases [PassivePhase + 1][]func()
+	// operation names of mutations
+	ops []string
+	// next phase to run
+	next CommitPhase
+}
+
+func (f *frame) metrics() CommitMetrics {
+	return CommitMetrics{
+		Mutations:      len(f.phases[MutationPhase]),
+		LayoutEffects:  len(f.phases[LayoutPhase]),
+		PassiveEffects: len(f.phases[PassivePhase]),
+	}
+}
+
 type commitQueue struct {
-	queue    chan func()
+	// DOM mutations
 	queueBuf []func()
-	rw       sync.RWMutex
+	// operation names of queued DOM mutations
+	opsBuf []string
+	// components which queued the DOM mutations, only kept when tracing
+	ownersBuf []*VNode
+	// the component rendering, owns the DOM mutations queued
+	owner *VNode
+	// layout effects, run after all DOM mutations committed
+	layoutBuf []func()
+	// passive effects, run after painted
+	passiveBuf []func()
+	rw         sync.RWMutex
+
+	// committed, but not all phases run
+	frames  []*frame
+	metrics Metrics
+	// schedules phases of commits, AnimationFrameScheduler by default
+	scheduler CommitScheduler
+	// to avoid running phases of different commits at same time
+	running sync.Mutex
+	// receives records of committed phases when set
+	tracer Tracer
 }
 
-func (q *commitQueue) push(fn func()) {
+func (q *commitQueue) push(op string, fn func()) {
 	q.rw.Lock()
 	q.queueBuf = append(q.queueBuf, fn)
+	q.opsBuf = append(q.opsBuf, op)
+	if q.tracer != nil {
+		q.ownersBuf = append(q.ownersBuf, q.owner)
+	}
 	q.rw.Unlock()
 }
 
-func (q *commitQueue) runIfExists() {
+// own sets the owner of DOM mutations queued later, returns the previous owner.
+func (q *commitQueue) own(owner *VNode) *VNode {
 	q.rw.Lock()
-	func(queue []func()) {
-		actionQueueBufferEach(queue, bufSize, func(buf []func()) {
-			browser.RequestAnimationFrame(func() {
-				for i := range buf {
-					buf[i]()
-				}
-			})
-		})
-	}(q.queueBuf)
-	q.queueBuf = make([]func(), 0)
-	q.rw.Unlock()
+	defer q.rw.Unlock()
+
+	prev := q.owner
+	q.owner = owner
+	return prev
 }
 
-const bufSize = 2048
+// operationsOf counts DOM mutations queued by owner after m, only available when tracing.
+func (q *commitQueue) operationsOf(owner *VNode, m commitMark) map[string]int {
+	q.rw.RLock()
+	defer q.rw.RUnlock()
 
-func actionQueueBufferEach(queue []func(), bufSize int, each func(queue []func())) {
-	n := len(queue)
-	partN := n / bufSize
-	for i := 0; i < partN+1; i++ {
-		left := i * bufSize
-		if left > n-1 {
-			break
+	ops := map[string]int{}
+	for i := m.queue; i < len(q.ownersBuf); i++ {
+		if q.ownersBuf[i] == owner {
+			ops[q.opsBuf[i]]++
 		}
-		right := (i + 1) * bufSize
-		if right > n {
-			right = n
+	}
+	return ops
+}
+
+// commitMark is the position of queued functions
+type commitMark struct {
+	queue, layout, passive int
+}
+
+func (q *commitQueue) mark() commitMark {
+	q.rw.RLock()
+	defer q.rw.RUnlock()
+	return commitMark{queue: len(q.queueBuf), layout: len(q.layoutBuf), passive: len(q.passiveBuf)}
+}
+
+// rollback drops functions queued after m
+func (q *commitQueue) rollback(m commitMark) {
+	q.rw.Lock()
+	defer q.rw.Unlock()
+
+	if m.queue <= len(q.queueBuf) {
+		q.queueBuf = q.queueBuf[:m.queue]
+		q.opsBuf = q.opsBuf[:m.queue]
+		if m.queue <= len(q.ownersBuf) {
+			q.ownersBuf = q.ownersBuf[:m.queue]
 		}
-		each(queue[i*bufSize : right])
+	}
+	if m.layout <= len(q.layoutBuf) {
+		q.layoutBuf = q.layoutBuf[:m.layout]
+	}
+	if m.passive <= len(q.passiveBuf) {
+		q.passiveBuf = q.passiveBuf[:m.passive]
 	}
 }
 
-func (q *commitQueue) ForceCommit() {
-	q.runIfExists()
+// take moves all queued functions into a new frame,
+// returns nil when nothing queued.
+func (q *commitQueue) take() *frame {
+	q.rw.Lock()
+	defer q.rw.Unlock()
+
+	if len(q.queueBuf)+len(q.layoutBuf)+len(q.passiveBuf) == 0 {
+		return nil
+	}
+
+	f := &frame{ops: q.opsBuf}
+	f.phases[MutationPhase], f.phases[LayoutPhase], f.phases[PassivePhase] = q.queueBuf, q.layoutBuf, q.passiveBuf
+	q.queueBuf, q.layoutBuf, q.passiveBuf, q.opsBuf, q.ownersBuf = nil, nil, nil, nil, nil
+
+	q.frames = append(q.frames, f)
+
+	m := f.metrics()
+	q.metrics.Commits++
+	q.metrics.Last = m
+	q.metrics.Total = q.metrics.Total.add(m)
+	if m.Mutations > q.metrics.Max.Mutations {
+		q.metrics.Max.Mutations = m.Mutations
+	}
+	if m.LayoutEffects > q.metrics.Max.LayoutEffects {
+		q.metrics.Max.LayoutEffects = m.LayoutEffects
+	}
+	if m.PassiveEffects > q.metrics.Max.PassiveEffects {
+		q.metrics.Max.PassiveEffects = m.PassiveEffects
+	}
+
+	return f
 }
 
-func (q *commitQueue) Close() error {
-	close(q.queue)
-	return nil
+// Metrics returns metrics of all commits
+func (q *commitQueue) Metrics() Metrics {
+	q.rw.RLock()
+	defer q.rw.RUnlock()
+	return q.metrics
 }
 
-func (q *commitQueue) Start() {
-	q.queue = make(chan func())
+// runUntil runs phases of frames in order, until the phase of f is done.
+func (q *commitQueue) runUntil(f *frame, phase CommitPhase) {
+	q.running.Lock()
+	defer q.running.Unlock()
 
-	go func() {
-	LOOP:
-		for {
-			select {
-			case fn, ok := <-q.queue:
-				if !ok {
-					break LOOP
-				}
-				q.push(fn)
-			case <-time.After(10 * time.Millisecond):
-				q.runIfExists()
-			}
+	for {
+		q.rw.Lock()
+		if f.next > phase || len(q.frames) == 0 {
+			q.rw.Unlock()
+			return
+		}
+		g := q.frames[0]
+		p := g.next
+		fns := g.phases[p]
+		g.next++
+		if g.next > PassivePhase {
+			q.frames = q.frames[1:]
 		}
-	}()
+		q.rw.Unlock()
+
+		if q.tracer != nil && len(fns) > 0 {
+			q.traceRun(g, p, fns)
+			continue
+		}
+
+		for i := range fns {
+			fns[i]()
+		}
+	}
+}
+
+func (q *commitQueue) traceRun(f *frame, phase CommitPhase, fns []func()) {
+	c := CommitRecord{Phase: phase, Start: time.Now(), Count: len(fns)}
+
+	if phase == MutationPhase {
+		c.Operations = map[string]int{}
+		for _, op := range f.ops {
+			c.Operations[op]++
+		}
+	}
+
+	for i := range fns {
+		fns[i]()
+	}
+
+	c.Duration = time.Since(c.Start)
+	q.tracer.TraceCommit(c)
+}
+
+// commit schedules all queued functions as one commit, and returns the count of them.
+// mutations and layout effects run in one scheduled call, passive effects run in the next one.
+func (q *commitQueue) commit() int {
+	f := q.take()
+	if f == nil {
+		return 0
+	}
+
+	s := q.scheduler
+	if s == nil {
+		s = AnimationFrameScheduler
+	}
+
+	s.Schedule(func() {
+		q.runUntil(f, LayoutPhase)
+
+		if len(f.phases[PassivePhase]) == 0 {
+			q.runUntil(f, PassivePhase)
+			return
+		}
+
+		// next frame, after painted
+		s.Schedule(func() {
+			q.runUntil(f, PassivePhase)
+		})
+	})
+
+	m := f.metrics()
+	return m.Mutations + m.LayoutEffects + m.PassiveEffects
 }
 
-func (q *commitQueue) Dispatch(fn func()) {
-	q.queue <- fn
+// maxForceCommitRounds limits the rounds of ForceCommit,
+// to avoid infinite loop when effects always updating states.
+const maxForceCommitRounds = 50
+
+// ForceCommit runs all phases of scheduled commits and queued functions synchronously,
+// including the functions queued while committing.
+func (q *commitQueue) ForceCommit() {
+	for i := 0; i < maxForceCommitRounds; i++ {
+		q.take()
+
+		q.rw.RLock()
+		n := len(q.frames)
+		var last *frame
+		if n > 0 {
+			last = q.frames[n-1]
+		}
+		q.rw.RUnlock()
+
+		if last == nil {
+			return
+		}
+
+		q.runUntil(last, PassivePhase)
+	}
+}
+
+// Dispatch queues the DOM mutation fn synchronously, op names the mutation for tracing,
+// so ForceCommit will always run all dispatched before.
+func (q *commitQueue) Dispatch(op string, fn func()) {
+	q.push(op, fn)
+}
+
+// DispatchLayout queues fn to run after all DOM mutations committed
+func (q *commitQueue) DispatchLayout(fn func()) {
+	q.rw.Lock()
+	q.layoutBuf = append(q.layoutBuf, fn)
+	q.rw.Unlock()
+}
+
+// DispatchPassive queues fn to run after painted
+func (q *commitQueue) DispatchPassive(fn func()) {
+	q.rw.Lock()
+	q.passiveBuf = append(q.passiveBuf, fn)
+	q.rw.Unlock()
 }
@@ -6,22 +6,81 @@ import (
 	"github.com/onsi/gomega"
 )
 
-func Test_actionQueueBufferEach(t *testing.T) {
-	q := []func(){
-		func() {},
-		func() {},
-		func() {},
-		func() {},
-		func() {},
+func TestCommitQueue(t *testing.T) {
+	logs := make([]string, 0)
+
+	log := func(s string) func() {
+		return func() {
+			logs = append(logs, s)
+		}
 	}
 
-	bufLens := make([]int, 0)
+	t.Run("should run phases of commits in order", func(t *testing.T) {
+		logs = logs[:0]
+
+		s := &ManualScheduler{}
+		q := &commitQueue{scheduler: s}
+
+		q.DispatchPassive(log("passive 1"))
+		q.DispatchLayout(log("layout 1"))
+		q.Dispatch("test", log("mutation 1"))
+		gomega.NewWithT(t).Expect(q.commit()).To(gomega.Equal(3))
+
+		q.Dispatch("test", log("mutation 2"))
+		q.DispatchPassive(log("passive 2"))
+		gomega.NewWithT(t).Expect(q.commit()).To(gomega.Equal(2))
+
+		gomega.NewWithT(t).Expect(logs).To(gomega.HaveLen(0))
+
+		s.Flush()
+
+		gomega.NewWithT(t).Expect(logs).To(gomega.Equal([]string{
+			"mutation 1", "layout 1", "passive 1",
+			"mutation 2", "passive 2",
+		}))
+		gomega.NewWithT(t).Expect(q.frames).To(gomega.HaveLen(0))
+	})
+
+	t.Run("should run scheduled commits before force commit", func(t *testing.T) {
+		logs = logs[:0]
 
-	actionQueueBufferEach(q, 2, func(queue []func()) {
-		bufLens = append(bufLens, len(queue))
+		s := &ManualScheduler{}
+		q := &commitQueue{scheduler: s}
+
+		q.Dispatch("test", log("mutation 1"))
+		q.DispatchPassive(log("passive 1"))
+		q.commit()
+
+		q.Dispatch("test", log("mutation 2"))
+		q.ForceCommit()
+
+		gomega.NewWithT(t).Expect(logs).To(gomega.Equal([]string{
+			"mutation 1", "passive 1", "mutation 2",
+		}))
+
+		s.Flush()
+
+		gomega.NewWithT(t).Expect(logs).To(gomega.HaveLen(3))
 	})
 
-	gomega.NewWithT(t).Expect(bufLens).To(gomega.Equal([]int{
-		2, 2, 1,
-	}))
+	t.Run("should count queued functions of each commit", func(t *testing.T) {
+		q := &commitQueue{scheduler: &ManualScheduler{}}
+
+		for i := 0; i < 3000; i++ {
+			q.Dispatch("test", func() {})
+		}
+		q.DispatchLayout(func() {})
+		q.commit()
+
+		q.Dispatch("test", func() {})
+		q.DispatchPassive(func() {})
+		q.ForceCommit()
+
+		gomega.NewWithT(t).Expect(q.Metrics()).To(gomega.Equal(Metrics{
+			Commits: 2,
+			Last:    CommitMetrics{Mutations: 1, PassiveEffects: 1},
+			Max:     CommitMetrics{Mutations: 3000, LayoutEffects: 1, PassiveEffects: 1},
+			Total:   CommitMetrics{Mutations: 3001, LayoutEffects: 1, PassiveEffects: 1},
+		}))
+	})
 }
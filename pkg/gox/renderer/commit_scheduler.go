@@ -0,0 +1,70 @@
+package renderer
+
+import (
+	"sync"
+
+	"github.com/go-courier/gox/pkg/browser"
+)
+
+// CommitScheduler schedules phases of commits,
+// the scheduled functions must be called in the order of scheduling.
+type CommitScheduler interface {
+	Schedule(fn func())
+}
+
+// CommitSchedulerFunc adapts func as CommitScheduler
+type CommitSchedulerFunc func(fn func())
+
+func (f CommitSchedulerFunc) Schedule(fn func()) {
+	f(fn)
+}
+
+var (
+	// AnimationFrameScheduler commits in the next animation frame, it is the default CommitScheduler.
+	AnimationFrameScheduler CommitScheduler = CommitSchedulerFunc(browser.RequestAnimationFrame)
+	// MicrotaskScheduler commits in a microtask, before the browser takes back control.
+	MicrotaskScheduler CommitScheduler = CommitSchedulerFunc(browser.QueueMicrotask)
+)
+
+// ManualScheduler holds commits until Flush called, for tests to control the timing of commits.
+type ManualScheduler struct {
+	mu        sync.Mutex
+	scheduled []func()
+}
+
+func (s *ManualScheduler) Schedule(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scheduled = append(s.scheduled, fn)
+}
+
+// Pending returns the count of scheduled functions
+func (s *ManualScheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.scheduled)
+}
+
+// Flush calls all scheduled functions, including the ones scheduled while flushing,
+// and returns the count of them.
+func (s *ManualScheduler) Flush() int {
+	n := 0
+
+	for {
+		s.mu.Lock()
+		scheduled := s.scheduled
+		s.scheduled = nil
+		s.mu.Unlock()
+
+		if len(scheduled) == 0 {
+			return n
+		}
+
+		for i := range scheduled {
+			scheduled[i]()
+		}
+		n += len(scheduled)
+	}
+}
@@ -0,0 +1,275 @@
+package renderer
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	. "github.com/go-courier/gox/pkg/dom"
+)
+
+// propNodeID is the property of host node to hold the id for event delegation
+const propNodeID = "__goxNodeID"
+
+// node ids are unique between roots, to avoid mixing handlers of nested roots
+var nodeIDSeq int64
+
+func nextNodeID() int {
+	return int(atomic.AddInt64(&nodeIDSeq, 1))
+}
+
+// parseEventAttr parses attr key like onClick or onClickCapture.
+func parseEventAttr(key string) (eventType string, capture bool, ok bool) {
+	if len(key) <= 2 || !strings.HasPrefix(key, "on") || !(key[2] >= 'A' && key[2] <= 'Z') {
+		return "", false, false
+	}
+
+	name := key[2:]
+
+	if strings.HasSuffix(name, "Capture") && name != "Capture" {
+		name = strings.TrimSuffix(name, "Capture")
+		capture = true
+	}
+
+	eventType = strings.ToLower(name)
+
+	if eventType == "doubleclick" {
+		eventType = "dblclick"
+	}
+
+	return eventType, capture, true
+}
+
+// nonBubblingEvents never bubble to mount points,
+// so they are delegated by capture listeners, and only handled by handlers of targets or capture handlers.
+var nonBubblingEvents = map[string]bool{
+	"focus":        true,
+	"blur":         true,
+	"mouseenter":   true,
+	"mouseleave":   true,
+	"pointerenter": true,
+	"pointerleave": true,
+	"scroll":       true,
+	"load":         true,
+	"error":        true,
+}
+
+func toEventHandler(v interface{}) (func(e Event), bool) {
+	switch fn := v.(type) {
+	case func(e Event):
+		return fn, true
+	case func():
+		return func(e Event) {
+			fn()
+		}, true
+	}
+	return nil, false
+}
+
+type eventHandlerKey struct {
+	eventType string
+	capture   bool
+}
+
+type eventHandlers map[eventHandlerKey]func(e Event)
+
+// eventDelegator holds all handlers of host nodes,
+// and dispatches events through single listener of each event type on mount points.
+type eventDelegator struct {
+	rw       sync.RWMutex
+	ids      map[Node]int
+	handlers map[int]eventHandlers
+	// mount point => event type => listener
+	listeners map[Element]map[string]func(e Event)
+	// mount points which ids are set for event delegation
+	mountPoints map[Element]bool
+}
+
+func (d *eventDelegator) init() {
+	if d.ids == nil {
+		d.ids = map[Node]int{}
+		d.handlers = map[int]eventHandlers{}
+		d.listeners = map[Element]map[string]func(e Event){}
+		d.mountPoints = map[Element]bool{}
+	}
+}
+
+func (r *Root) setEventHandler(mountPoint Element, node Element, eventType string, capture bool, handler func(e Event)) {
+	d := &r.events
+
+	d.rw.Lock()
+	defer d.rw.Unlock()
+
+	d.init()
+
+	id, ok := d.ids[node]
+	if !ok {
+		id = nextNodeID()
+		d.ids[node] = id
+
+		r.setProperty(node, propNodeID, id)
+	}
+
+	if d.handlers[id] == nil {
+		d.handlers[id] = eventHandlers{}
+	}
+
+	d.handlers[id][eventHandlerKey{eventType: eventType, capture: capture}] = handler
+
+	if d.listeners[mountPoint] == nil {
+		d.listeners[mountPoint] = map[string]func(e Event){}
+
+		if nodeID(mountPoint) == 0 {
+			d.mountPoints[mountPoint] = true
+			r.setProperty(mountPoint, propNodeID, nextNodeID())
+		}
+	}
+
+	if _, ok := d.listeners[mountPoint][eventType]; !ok {
+		listener := func(e Event) {
+			r.dispatchEvent(mountPoint, eventType, e)
+		}
+		d.listeners[mountPoint][eventType] = listener
+
+		r.cq.Dispatch("addEventListener", func() {
+			mountPoint.AddEventListener(eventType, listener, nonBubblingEvents[eventType])
+		})
+	}
+}
+
+func (r *Root) removeEventHandler(node Element, eventType string, capture bool) {
+	d := &r.events
+
+	d.rw.Lock()
+	defer d.rw.Unlock()
+
+	if id, ok := d.ids[node]; ok {
+		delete(d.handlers[id], eventHandlerKey{eventType: eventType, capture: capture})
+	}
+}
+
+// releaseEventHandlers drops all handlers of the removed node
+func (r *Root) releaseEventHandlers(node Element) {
+	d := &r.events
+
+	d.rw.Lock()
+	defer d.rw.Unlock()
+
+	if id, ok := d.ids[node]; ok {
+		delete(d.handlers, id)
+		delete(d.ids, node)
+	}
+}
+
+// removeEventListeners removes the delegated listeners and ids from all mount points, and drops all handlers.
+func (r *Root) removeEventListeners() {
+	d := &r.events
+
+	d.rw.Lock()
+	defer d.rw.Unlock()
+
+	for mountPoint, listeners := range d.listeners {
+		for eventType, listener := range listeners {
+			mountPoint.RemoveEventListener(eventType, listener, nonBubblingEvents[eventType])
+		}
+	}
+
+	for mountPoint := range d.mountPoints {
+		mountPoint.Set(propNodeID, nil)
+	}
+
+	d.ids = nil
+	d.handlers = nil
+	d.listeners = nil
+	d.mountPoints = nil
+}
+
+func nodeID(n Node) int {
+	switch id := n.Get(propNodeID).(type) {
+	case int:
+		return id
+	case float64:
+		// from js
+		return int(id)
+	}
+	return 0
+}
+
+func (r *Root) dispatchEvent(mountPoint Element, eventType string, e Event) {
+	target, ok := e.Target().(Node)
+	if !ok {
+		return
+	}
+
+	mountPointID := nodeID(mountPoint)
+
+	type handled struct {
+		node    Node
+		handler func(e Event)
+	}
+
+	capturing := make([]handled, 0)
+	bubbling := make([]handled, 0)
+
+	r.events.rw.RLock()
+	for n := target; n != nil; n = n.ParentNode() {
+		id := nodeID(n)
+		if id == mountPointID {
+			break
+		}
+		if handlers, ok := r.events.handlers[id]; ok {
+			if h, ok := handlers[eventHandlerKey{eventType: eventType, capture: true}]; ok {
+				capturing = append([]handled{{node: n, handler: h}}, capturing...)
+			}
+			if nonBubblingEvents[eventType] && n != target {
+				continue
+			}
+			if h, ok := handlers[eventHandlerKey{eventType: eventType}]; ok {
+				bubbling = append(bubbling, handled{node: n, handler: h})
+			}
+		}
+	}
+	r.events.rw.RUnlock()
+
+	se := &SyntheticEvent{Event: e}
+
+	// updates caused by user input should be rendered as soon as possible
+	r.RunWithPriority(UserBlockingPriority, func() {
+		for _, list := range [][]handled{capturing, bubbling} {
+			for _, h := range list {
+				if se.propagationStopped {
+					return
+				}
+				se.currentTarget = h.node
+				h.handler(se)
+			}
+		}
+	})
+}
+
+// SyntheticEvent wraps the native event for handlers declared by onX attrs.
+type SyntheticEvent struct {
+	Event
+	currentTarget      Node
+	propagationStopped bool
+}
+
+// Native returns the native event
+func (e *SyntheticEvent) Native() Event {
+	return e.Event
+}
+
+// CurrentTarget returns node of the handler which is handling the event
+func (e *SyntheticEvent) CurrentTarget() EventTarget {
+	return e.currentTarget
+}
+
+func (e *SyntheticEvent) StopPropagation() {
+	e.propagationStopped = true
+	e.Event.StopPropagation()
+}
+
+func (e *SyntheticEvent) StopImmediatePropagation() {
+	e.propagationStopped = true
+	e.Event.StopImmediatePropagation()
+}
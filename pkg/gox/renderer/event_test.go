@@ -0,0 +1,147 @@
+package renderer
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/onsi/gomega"
+)
+
+func Test_parseEventAttr(t *testing.T) {
+	cases := []struct {
+		key       string
+		eventType string
+		capture   bool
+		ok        bool
+	}{
+		{"onClick", "click", false, true},
+		{"onClickCapture", "click", true, true},
+		{"onDoubleClick", "dblclick", false, true},
+		{"onion", "", false, false},
+		{"on", "", false, false},
+	}
+
+	for _, c := range cases {
+		eventType, capture, ok := parseEventAttr(c.key)
+		gomega.NewWithT(t).Expect([]interface{}{eventType, capture, ok}).To(gomega.Equal([]interface{}{c.eventType, c.capture, c.ok}))
+	}
+}
+
+func TestEventDelegation(t *testing.T) {
+	ctx := context.Background()
+	root := Document.CreateElement("body")
+	r := CreateRoot(root)
+
+	calls := make([]string, 0)
+
+	render := func(label string, stop bool) {
+		_ = r.Render(ctx, Div(
+			Attrs{
+				"onClick": func(e Event) {
+					calls = append(calls, "div "+label)
+				},
+				"onClickCapture": func() {
+					calls = append(calls, "div capture "+label)
+				},
+			},
+			Button(
+				Attr("onClick", func(e Event) {
+					calls = append(calls, "button "+label)
+					if stop {
+						e.StopPropagation()
+					}
+					gomega.NewWithT(t).Expect(e.(*SyntheticEvent).CurrentTarget()).To(gomega.Equal(e.Target()))
+				}),
+				"click",
+			),
+		))
+	}
+
+	click := func() {
+		button := root.FirstChild().FirstChild()
+		button.DispatchEvent(NewEvent("click", EventInit{Bubbles: true}))
+	}
+
+	t.Run("should capture and bubble", func(t *testing.T) {
+		render("1", false)
+		click()
+
+		gomega.NewWithT(t).Expect(calls).To(gomega.Equal([]string{"div capture 1", "button 1", "div 1"}))
+		gomega.NewWithT(t).Expect(root.FirstChild().(Element).GetAttributeNames()).To(gomega.HaveLen(0))
+	})
+
+	t.Run("should replace handlers and stop propagation", func(t *testing.T) {
+		calls = calls[0:0]
+
+		render("2", true)
+		click()
+
+		gomega.NewWithT(t).Expect(calls).To(gomega.Equal([]string{"div capture 2", "button 2"}))
+		gomega.NewWithT(t).Expect(r.events.listeners[root]).To(gomega.HaveLen(1))
+	})
+
+	t.Run("should remove handlers", func(t *testing.T) {
+		calls = calls[0:0]
+
+		_ = r.Render(ctx, Div(Button("click")))
+		click()
+
+		gomega.NewWithT(t).Expect(calls).To(gomega.HaveLen(0))
+	})
+}
+
+func TestEventDelegationOfNonBubblingEvents(t *testing.T) {
+	ctx := context.Background()
+	root := Document.CreateElement("body")
+	r := CreateRoot(root)
+	defer r.Close()
+
+	calls := make([]string, 0)
+
+	handle := func(name string) func(e Event) {
+		return func(e Event) {
+			calls = append(calls, name)
+		}
+	}
+
+	_ = r.Render(ctx, Div(
+		Attrs{"onFocus": handle("div focus"), "onFocusCapture": handle("div focus capture"), "onMouseEnter": handle("div mouseenter")},
+		Input(Attrs{"onFocus": handle("input focus"), "onBlur": handle("input blur"), "onMouseEnter": handle("input mouseenter")}),
+	))
+
+	div := root.FirstChild()
+	input := div.FirstChild()
+
+	t.Run("should handle by target and capture handlers", func(t *testing.T) {
+		calls = calls[0:0]
+
+		input.DispatchEvent(NewEvent("focus"))
+		input.DispatchEvent(NewEvent("blur"))
+
+		gomega.NewWithT(t).Expect(calls).To(gomega.Equal([]string{"div focus capture", "input focus", "input blur"}))
+	})
+
+	t.Run("should handle mouseenter of each element entered", func(t *testing.T) {
+		calls = calls[0:0]
+
+		div.DispatchEvent(NewEvent("mouseenter"))
+		input.DispatchEvent(NewEvent("mouseenter"))
+
+		gomega.NewWithT(t).Expect(calls).To(gomega.Equal([]string{"div mouseenter", "input mouseenter"}))
+	})
+
+	t.Run("should release listeners and id of mount point when unmounted", func(t *testing.T) {
+		calls = calls[0:0]
+
+		gomega.NewWithT(t).Expect(nodeID(root)).NotTo(gomega.Equal(0))
+
+		_ = r.Unmount()
+
+		gomega.NewWithT(t).Expect(nodeID(root)).To(gomega.Equal(0))
+
+		input.DispatchEvent(NewEvent("focus"))
+		gomega.NewWithT(t).Expect(calls).To(gomega.HaveLen(0))
+	})
+}
@@ -0,0 +1,14 @@
+package renderer_test
+
+import (
+	"bytes"
+
+	. "github.com/go-courier/gox/pkg/dom"
+)
+
+// htmlOf returns the html of n, shared by tests to assert rendered nodes
+func htmlOf(n Node) string {
+	buf := bytes.NewBuffer(nil)
+	RenderToHTML(buf, n)
+	return buf.String()
+}
@@ -0,0 +1,353 @@
+package renderer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-courier/gox/pkg/gox/internal"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+)
+
+// HydrateRoot creates Root on the server-rendered children of root.
+// Existing nodes are adopted instead of recreated,
+// mismatched nodes are replaced and reported as *HydrationError.
+func HydrateRoot(ctx context.Context, root Element, vnode *VNode, options ...RootOption) (*Root, error) {
+	r := CreateRoot(root, options...)
+
+	h := &hydration{}
+	h.enter(root)
+
+	_ = r.exclusive(func() {
+		r.hydration = h
+
+		nextRoot := Portal(r.root.Node)(vnode)
+		r.patchVNode(ctx, r.root, nextRoot)
+		r.root = nextRoot
+
+		h.leave(r, nextRoot)
+		r.hydration = nil
+
+		r.flushContextUpdates()
+
+		r.cq.ForceCommit()
+	})
+
+	if len(h.mismatches) > 0 {
+		return r, &HydrationError{Mismatches: h.mismatches}
+	}
+	return r, nil
+}
+
+type HydrationMismatch struct {
+	// Path to the offending VNode
+	Path   string
+	Reason string
+}
+
+func (m HydrationMismatch) String() string {
+	return m.Path + ": " + m.Reason
+}
+
+type HydrationError struct {
+	Mismatches []HydrationMismatch
+}
+
+func (e *HydrationError) Error() string {
+	b := &strings.Builder{}
+	b.WriteString("hydration mismatched:")
+	for i := range e.Mismatches {
+		b.WriteString("\n\t")
+		b.WriteString(e.Mismatches[i].String())
+	}
+	return b.String()
+}
+
+type hydrationCursor struct {
+	parent Element
+	next   Node
+}
+
+type hydration struct {
+	cursors    []*hydrationCursor
+	mismatches []HydrationMismatch
+}
+
+func (h *hydration) enter(parent Element) {
+	h.cursors = append(h.cursors, &hydrationCursor{parent: parent, next: parent.FirstChild()})
+}
+
+// leave removes the rest nodes which not claimed by any VNode
+func (h *hydration) leave(r *Root, vnode *VNode) {
+	c := h.current()
+	h.cursors = h.cursors[0 : len(h.cursors)-1]
+
+	for n := skipComments(c.next); n != nil; n = skipComments(n.NextSibling()) {
+		h.report(vnode, "unexpected %s", describeNode(n))
+		r.removeChild(c.parent, n)
+	}
+}
+
+func (h *hydration) current() *hydrationCursor {
+	return h.cursors[len(h.cursors)-1]
+}
+
+func (h *hydration) report(vnode *VNode, format string, args ...interface{}) {
+	h.mismatches = append(h.mismatches, HydrationMismatch{
+		Path:   vnodePath(vnode),
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+// insert puts the fresh node at the hydrating position
+func (h *hydration) insert(r *Root, n Node) {
+	c := h.current()
+	r.insertBefore(c.parent, n, c.next)
+}
+
+// claim takes the next node if it is as expected,
+// or removes it as mismatched.
+func (h *hydration) claim(r *Root, vnode *VNode, nodeType NodeType, nodeName string) Node {
+	c := h.current()
+
+	n := skipComments(c.next)
+	if n == nil {
+		c.next = nil
+		// empty text node will not be rendered on server side
+		if !(nodeType == TEXT_NODE && vnode.Type == internal.Text("")) {
+			h.report(vnode, "missing %s", nodeName)
+		}
+		return nil
+	}
+
+	c.next = n.NextSibling()
+
+	if n.NodeType() != nodeType || n.NodeName() != nodeName {
+		h.report(vnode, "expect %s, but got %s", nodeName, describeNode(n))
+		r.removeChild(c.parent, n)
+		return nil
+	}
+
+	return n
+}
+
+// claimIds takes the start marker of the rendering component, returns ids generated on server side.
+// Markers closing components and separating texts before it are skipped.
+func (h *hydration) claimIds() []string {
+	c := h.current()
+
+	for n := c.next; n != nil && n.NodeType() == COMMENT_NODE; n = n.NextSibling() {
+		data := n.TextContent()
+
+		if strings.HasPrefix(data, markerComponentStart) {
+			c.next = n.NextSibling()
+			return idsOfComponentStartMarker(data)
+		}
+
+		if data != markerComponentEnd && data != markerTextSeparator {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *Root) hydrate(ctx context.Context, h *hydration, vnode *VNode) bool {
+	switch x := vnode.Type.(type) {
+	case internal.Text:
+		n := h.claim(r, vnode, TEXT_NODE, "#text")
+		if n == nil {
+			return false
+		}
+		if text := n.TextContent(); text != string(x) {
+			h.report(vnode, "expect text %q, but got %q", string(x), text)
+			r.setTextContent(n, string(x))
+		}
+		vnode.Node = n.(Element)
+		return true
+	case internal.Element:
+		n := h.claim(r, vnode, ELEMENT_NODE, string(x))
+		if n == nil {
+			return false
+		}
+		vnode.Node = n.(Element)
+		if r.hydrateAttrs(h, vnode) {
+			// innerHTML or value of textarea, not rendered as children
+			return true
+		}
+
+		h.enter(vnode.Node)
+		r.addVNodes(ctx, vnode.Node, nil, vnode.Children, 0, len(vnode.Children)-1)
+		h.leave(r, vnode)
+		return true
+	}
+
+	if vnode.IsRoot {
+		// portal will not be rendered on server side
+		return false
+	}
+
+	r.addVNodes(ctx, vnode.MountedNode(), nil, vnode.Children, 0, len(vnode.Children)-1)
+	return true
+}
+
+// hydrateAttrs compares attributes of the server-rendered node with the ones RenderToString writes for vnode,
+// mismatches are patched as mounting, returns true when vnode has content like innerHTML instead of children.
+func (r *Root) hydrateAttrs(h *hydration, vnode *VNode) bool {
+	n := vnode.Node
+
+	attrs, _, hasContent := htmlAttrsOf(vnode)
+
+	for k, v := range vnode.Attrs {
+		if eventType, capture, ok := parseEventAttr(k); ok {
+			if handler, ok := toEventHandler(v); ok {
+				r.setEventHandler(mountPointOf(vnode), n, eventType, capture, handler)
+			}
+		}
+	}
+
+	existed := map[string]bool{}
+	names := make([]string, 0, len(attrs))
+
+	for _, name := range n.GetAttributeNames() {
+		existed[name] = true
+		names = append(names, name)
+	}
+	for name := range attrs {
+		if !existed[name] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == attrKey {
+			continue
+		}
+
+		expected, ok := AttrValue(name, attrs[name])
+		cur := n.GetAttribute(name)
+
+		switch {
+		case ok && !existed[name]:
+			h.report(vnode, "missing attribute %s", name)
+		case !ok && existed[name]:
+			h.report(vnode, "unexpected attribute %s", name)
+		case ok && expected != fmt.Sprint(cur):
+			h.report(vnode, "expect attribute %s=%s, but got %v", name, expected, cur)
+		default:
+			continue
+		}
+
+		r.hydrateAttr(vnode, name, cur, existed[name])
+	}
+
+	if hasContent {
+		r.hydrateContent(h, vnode)
+	}
+
+	return hasContent
+}
+
+// hydrateAttr patches the attribute name by the attr of vnode which writes it
+func (r *Root) hydrateAttr(vnode *VNode, name string, cur interface{}, existed bool) {
+	if name == "style" {
+		// the style string replaced by properties when style is a map
+		r.patchStyle(vnode, cur, vnode.Attrs[name], existed)
+		return
+	}
+
+	key := name
+	if _, ok := vnode.Attrs[key]; !ok && name == "class" {
+		key = "className"
+	}
+
+	if v, ok := vnode.Attrs[key]; ok {
+		r.setAttr(vnode, key, v, existed)
+		return
+	}
+
+	r.removeAttribute(vnode.Node, name)
+}
+
+// hydrateContent compares children of the server-rendered node with innerHTML, or value of textarea
+func (r *Root) hydrateContent(h *hydration, vnode *VNode) {
+	key := "innerHTML"
+	content := &strings.Builder{}
+
+	if v, ok := vnode.Attrs[key]; ok && v != nil {
+		for c := vnode.Node.FirstChild(); c != nil; c = c.NextSibling() {
+			RenderToHTML(content, c)
+		}
+	} else {
+		key = "value"
+		for c := vnode.Node.FirstChild(); c != nil; c = c.NextSibling() {
+			content.WriteString(c.TextContent())
+		}
+	}
+
+	if expected := fmt.Sprint(vnode.Attrs[key]); content.String() != expected {
+		h.report(vnode, "expect %s %q, but got %q", key, expected, content.String())
+		r.setAttr(vnode, key, vnode.Attrs[key], true)
+	}
+}
+
+func skipComments(n Node) Node {
+	for n != nil && n.NodeType() == COMMENT_NODE {
+		n = n.NextSibling()
+	}
+	return n
+}
+
+func describeNode(n Node) string {
+	if n.NodeType() == TEXT_NODE {
+		return fmt.Sprintf("#text %q", n.TextContent())
+	}
+	return n.NodeName()
+}
+
+func vnodePath(vnode *VNode) string {
+	parts := make([]string, 0)
+
+	for vn := vnode; vn != nil && !vn.IsRoot; vn = vn.Parent {
+		if _, ok := vn.Type.(internal.Fragment); ok && vn != vnode {
+			continue
+		}
+		parts = append(parts, vnodeName(vn))
+	}
+
+	b := &strings.Builder{}
+	for i := len(parts) - 1; i >= 0; i-- {
+		b.WriteString(parts[i])
+		if i > 0 {
+			b.WriteString(" > ")
+		}
+	}
+	return b.String()
+}
+
+func vnodeName(vnode *VNode) string {
+	name := typeName(vnode)
+
+	if vnode.Key != "" {
+		return name + "[" + string(vnode.Key) + "]"
+	}
+
+	if vnode.Parent != nil {
+		idx := 0
+		for _, c := range vnode.Parent.Children {
+			if c == vnode {
+				return fmt.Sprintf("%s:%d", name, idx)
+			}
+			if _, ok := c.(*VNode); ok {
+				idx++
+			}
+		}
+	}
+
+	return name
+}
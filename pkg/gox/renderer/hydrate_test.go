@@ -0,0 +1,164 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-courier/gox/pkg/css"
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+func TestHydrateRoot(t *testing.T) {
+	ctx := context.Background()
+
+	serverRendered := func(vnode *VNode) Element {
+		root := Document.CreateElement("body")
+		_ = renderer.CreateRoot(root).Render(ctx, vnode)
+		return root
+	}
+
+	t.Run("should adopt existed nodes", func(t *testing.T) {
+		root := serverRendered(Div(Attrs{"role": "value"}, H(App{Text: "app"})(), Span("1")))
+		div := root.FirstChild()
+
+		ref := &Ref[Element]{}
+
+		r, err := renderer.HydrateRoot(ctx, root, Div(Attrs{"role": "value"}, H(App{Text: "app"})(), Span(ref, "1")))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(root.FirstChild()).To(gomega.BeIdenticalTo(div))
+		gomega.NewWithT(t).Expect(ref.Current).To(gomega.BeIdenticalTo(div.LastChild()))
+
+		_ = r.Render(ctx, Div(Attrs{"role": "value"}, H(App{Text: "app updated"})(), Span("1")))
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div role="value"><div>hello app updated</div><span>1</span></div></body>`))
+		gomega.NewWithT(t).Expect(root.FirstChild()).To(gomega.BeIdenticalTo(div))
+	})
+
+	t.Run("should report and patch mismatches", func(t *testing.T) {
+		root := serverRendered(Div(Attrs{"role": "value"}, Span("1"), P("2"), I("3")))
+
+		_, err := renderer.HydrateRoot(ctx, root, Div(Attrs{"role": "other"}, Span("x"), B("2")))
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+
+		mismatches := err.(*renderer.HydrationError).Mismatches
+		gomega.NewWithT(t).Expect(mismatches).To(gomega.Equal([]renderer.HydrationMismatch{
+			{Path: "div:0", Reason: "expect attribute role=other, but got value"},
+			{Path: "div:0 > span:0 > #text:0", Reason: `expect text "x", but got "1"`},
+			{Path: "div:0 > b:1", Reason: "expect b, but got p"},
+			{Path: "div:0", Reason: "unexpected i"},
+		}))
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div role="other"><span>x</span><b>2</b></div></body>`))
+	})
+
+	t.Run("should adopt attributes written by RenderToString", func(t *testing.T) {
+		form := func() *VNode {
+			return Form(
+				Div(Attrs{"className": "a", "innerHTML": "<b>trusted</b>"}),
+				Textarea(Attrs{"value": "text"}),
+				Button(Attrs{"disabled": false, "aria-pressed": true, "type": "submit"}),
+			)
+		}
+
+		root := streamRendered(t, form())
+		div := root.FirstChild().FirstChild()
+		trusted := div.FirstChild()
+		rendered := htmlOf(root)
+
+		r, err := renderer.HydrateRoot(ctx, root, form())
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		defer r.Close()
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(rendered))
+		gomega.NewWithT(t).Expect(div.FirstChild()).To(gomega.BeIdenticalTo(trusted))
+		gomega.NewWithT(t).Expect(root.QuerySelector("button").GetAttributeNames()).NotTo(gomega.ContainElement("disabled"))
+	})
+
+	t.Run("should bind event handlers without mismatches", func(t *testing.T) {
+		clicked := 0
+		button := func() *VNode {
+			return Button(Attrs{"onClick": func() { clicked++ }}, "click")
+		}
+
+		root := streamRendered(t, button())
+
+		r, err := renderer.HydrateRoot(ctx, root, button())
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		defer r.Close()
+
+		root.FirstChild().DispatchEvent(NewEvent("click", EventInit{Bubbles: true}))
+		gomega.NewWithT(t).Expect(clicked).To(gomega.Equal(1))
+	})
+
+	t.Run("should patch mismatched properties as attributes written", func(t *testing.T) {
+		root := streamRendered(t, Form(
+			Div(Attrs{"className": "a", "innerHTML": "<b>a</b>"}),
+			Textarea(Attrs{"value": "a"}),
+			Button(Attrs{"disabled": true}),
+		))
+
+		r, err := renderer.HydrateRoot(ctx, root, Form(
+			Div(Attrs{"className": "b", "innerHTML": "<b>b</b>"}),
+			Textarea(Attrs{"value": "b"}),
+			Button(Attrs{"disabled": false}),
+		))
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+		defer r.Close()
+
+		mismatches := err.(*renderer.HydrationError).Mismatches
+		gomega.NewWithT(t).Expect(mismatches).To(gomega.Equal([]renderer.HydrationMismatch{
+			{Path: "form:0 > div:0", Reason: "expect attribute class=b, but got a"},
+			{Path: "form:0 > div:0", Reason: `expect innerHTML "<b>b</b>", but got "<b>a</b>"`},
+			{Path: "form:0 > textarea:1", Reason: `expect value "b", but got "a"`},
+			{Path: "form:0 > button:2", Reason: "unexpected attribute disabled"},
+		}))
+
+		gomega.NewWithT(t).Expect(root.QuerySelector("div").Get("className")).To(gomega.Equal("b"))
+		gomega.NewWithT(t).Expect(root.QuerySelector("div").Get("innerHTML")).To(gomega.Equal("<b>b</b>"))
+		gomega.NewWithT(t).Expect(root.QuerySelector("textarea").Get("value")).To(gomega.Equal("b"))
+		gomega.NewWithT(t).Expect(root.QuerySelector("button").GetAttributeNames()).NotTo(gomega.ContainElement("disabled"))
+	})
+
+	t.Run("should compare style map by the style string written", func(t *testing.T) {
+		root := streamRendered(t, Div(css.CSS{"color": "red", "width": 10}))
+
+		r, err := renderer.HydrateRoot(ctx, root, Div(css.CSS{"color": "red", "width": 10}))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		defer r.Close()
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div style="color:red;width:10px;"></div></body>`))
+
+		t.Run("should patch mismatched properties", func(t *testing.T) {
+			root := streamRendered(t, Div(css.CSS{"color": "red", "width": 10}))
+
+			r, err := renderer.HydrateRoot(ctx, root, Div(css.CSS{"color": "blue"}))
+			gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+			defer r.Close()
+
+			gomega.NewWithT(t).Expect(err.(*renderer.HydrationError).Mismatches).To(gomega.Equal([]renderer.HydrationMismatch{
+				{Path: "div:0", Reason: "expect attribute style=color:blue;, but got color:red;width:10px;"},
+			}))
+			gomega.NewWithT(t).Expect(root.QuerySelector("div").GetAttribute("style")).To(gomega.Equal("color:blue;"))
+			gomega.NewWithT(t).Expect(root.QuerySelector("div").Style().GetPropertyValue("color")).To(gomega.Equal("blue"))
+		})
+	})
+}
+
+// streamRendered returns the body with nodes parsed from the html of RenderToString
+func streamRendered(t *testing.T, vnode *VNode) Element {
+	buf := bytes.NewBuffer(nil)
+	gomega.NewWithT(t).Expect(renderer.RenderToString(context.Background(), buf, vnode)).To(gomega.BeNil())
+
+	nodes, err := ParseFragment(buf, nil)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	body := Document.CreateElement("body")
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	return body
+}
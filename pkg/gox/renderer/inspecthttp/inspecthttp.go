@@ -0,0 +1,45 @@
+// Package inspecthttp serves renderer.Inspector by http,
+// out of the renderer, so net/http is only built into apps using it.
+package inspecthttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-courier/gox/pkg/gox/renderer"
+)
+
+// Handler returns the http.Handler of i,
+// which responds the tree for GET, and handles renderer.InspectRequest in JSON body for POST.
+func Handler(i *renderer.Inspector) http.Handler {
+	return &handler{i: i}
+}
+
+type handler struct {
+	i *renderer.Inspector
+}
+
+func (h *handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	r := renderer.InspectRequest{Action: "inspect"}
+
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		rw.Header().Set("Allow", "GET, POST")
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := h.i.Handle(r)
+
+	rw.Header().Set("Content-Type", "application/json")
+	if resp.Error != "" {
+		rw.WriteHeader(http.StatusBadRequest)
+	}
+	_ = json.NewEncoder(rw).Encode(resp)
+}
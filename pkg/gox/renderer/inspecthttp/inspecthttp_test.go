@@ -0,0 +1,81 @@
+package inspecthttp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/go-courier/gox/pkg/gox/renderer/inspecthttp"
+	"github.com/onsi/gomega"
+)
+
+type Counter struct {
+}
+
+func (Counter) Render(ctx context.Context, children ...interface{}) interface{} {
+	count, _ := UseState(ctx, 1)
+	return Div(count)
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+
+	root := Document.CreateElement("body")
+	r := renderer.CreateRoot(root)
+	defer r.Close()
+
+	_ = r.Render(ctx, Section(H(Counter{})()))
+
+	s := httptest.NewServer(inspecthttp.Handler(renderer.NewInspector(r)))
+	defer s.Close()
+
+	post := func(body string) (*http.Response, renderer.InspectResponse) {
+		resp, err := http.Post(s.URL, "application/json", strings.NewReader(body))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		defer resp.Body.Close()
+
+		ret := renderer.InspectResponse{}
+		_ = json.NewDecoder(resp.Body).Decode(&ret)
+		return resp, ret
+	}
+
+	t.Run("should respond the tree for GET", func(t *testing.T) {
+		resp, err := http.Get(s.URL)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		defer resp.Body.Close()
+
+		ret := renderer.InspectResponse{}
+		_ = json.NewDecoder(resp.Body).Decode(&ret)
+
+		gomega.NewWithT(t).Expect(resp.StatusCode).To(gomega.Equal(http.StatusOK))
+		gomega.NewWithT(t).Expect(ret.Tree.Children[0].Children[0].Name).To(gomega.Equal("Counter"))
+	})
+
+	t.Run("should set state for POST", func(t *testing.T) {
+		body, _ := json.Marshal(renderer.InspectRequest{Seq: 1, Action: "setState", ID: "0-0", State: json.RawMessage(`7`)})
+
+		resp, ret := post(string(body))
+
+		gomega.NewWithT(t).Expect(resp.StatusCode).To(gomega.Equal(http.StatusOK))
+		gomega.NewWithT(t).Expect(ret.Seq).To(gomega.Equal(1))
+		gomega.NewWithT(t).Expect(ret.Tree.Children[0].Children[0].Hooks[0].Value).To(gomega.Equal("UseState: 7"))
+
+		buf := bytes.NewBuffer(nil)
+		RenderToHTML(buf, root)
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(`<body><section><div>7</div></section></body>`))
+	})
+
+	t.Run("should fail for unsupported actions", func(t *testing.T) {
+		resp, ret := post(`{"action":"unknown"}`)
+
+		gomega.NewWithT(t).Expect(resp.StatusCode).To(gomega.Equal(http.StatusBadRequest))
+		gomega.NewWithT(t).Expect(ret.Error).NotTo(gomega.BeEmpty())
+	})
+}
@@ -0,0 +1,322 @@
+package renderer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-courier/gox/pkg/browser"
+	"github.com/go-courier/gox/pkg/gox/internal"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+)
+
+var ErrVNodeNotFound = errors.New("renderer: vnode not found")
+
+var ErrHookNotEditable = errors.New("renderer: hook not editable")
+
+// InspectedNode is the snapshot of a rendered VNode
+type InspectedNode struct {
+	// ID is the path of child indexes from the root joined by "-", like 0-1
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Key  string `json:"key,omitempty"`
+	// Text of text node
+	Text     string            `json:"text,omitempty"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Hooks    []InspectedHook   `json:"hooks,omitempty"`
+	Children []*InspectedNode  `json:"children,omitempty"`
+}
+
+// InspectedHook is the snapshot of a hook used by component
+type InspectedHook struct {
+	// Index of the hook in the component
+	Index int `json:"index"`
+	// Value describes the hook, like `UseState: 1`
+	Value string `json:"value"`
+	// State of UseState as JSON, which could be edited by Inspector.SetState
+	State json.RawMessage `json:"state,omitempty"`
+}
+
+// Inspect returns the snapshot of the rendered tree
+func (r *Root) Inspect() (*InspectedNode, error) {
+	var n *InspectedNode
+
+	if err := r.exclusive(func() {
+		n = inspectVNode(r.root, "")
+	}); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+func inspectVNode(vnode *VNode, id string) *InspectedNode {
+	n := &InspectedNode{
+		ID:   id,
+		Name: typeName(vnode),
+		Key:  string(vnode.Key),
+	}
+
+	if t, ok := vnode.Type.(internal.Text); ok {
+		n.Text = string(t)
+	}
+
+	if _, ok := vnode.Type.(internal.Element); ok && len(vnode.Attrs) > 0 {
+		n.Attrs = make(map[string]string, len(vnode.Attrs))
+		vnode.Attrs.Each(func(k string, v interface{}) {
+			if v != nil && reflect.TypeOf(v).Kind() == reflect.Func {
+				n.Attrs[k] = "func"
+				return
+			}
+			n.Attrs[k] = fmt.Sprint(v)
+		})
+	}
+
+	for i, hook := range vnode.Hooks() {
+		h := InspectedHook{Index: i, Value: fmt.Sprintf("%T", hook)}
+
+		if s, ok := hook.(fmt.Stringer); ok {
+			h.Value = s.String()
+		}
+
+		if s, ok := hook.(*internal.StateHook); ok {
+			if data, err := json.Marshal(s.State); err == nil {
+				h.State = data
+			}
+		}
+
+		n.Hooks = append(n.Hooks, h)
+	}
+
+	for i := range vnode.Children {
+		if child, ok := vnode.Children[i].(*VNode); ok {
+			childID := strconv.Itoa(i)
+			if id != "" {
+				childID = id + "-" + childID
+			}
+			n.Children = append(n.Children, inspectVNode(child, childID))
+		}
+	}
+
+	return n
+}
+
+// vnodeOf finds the rendered VNode by the id of InspectedNode
+func (r *Root) vnodeOf(id string) (*VNode, error) {
+	vnode := r.root
+	if id == "" {
+		return vnode, nil
+	}
+
+	for _, part := range strings.Split(id, "-") {
+		i, err := strconv.Atoi(part)
+		if err != nil || i < 0 || i >= len(vnode.Children) {
+			return nil, fmt.Errorf("%w: %s", ErrVNodeNotFound, id)
+		}
+		child, ok := vnode.Children[i].(*VNode)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrVNodeNotFound, id)
+		}
+		vnode = child
+	}
+
+	return vnode, nil
+}
+
+// HighlightAttr marks nodes of the highlighted VNode, overlays could style them by `[data-gox-inspected]`
+const HighlightAttr = "data-gox-inspected"
+
+// Inspector serves inspecting of a Root for devtools overlays or external pages,
+// by Handle directly, HTTP with JSON, or postMessage.
+type Inspector struct {
+	r *Root
+
+	mu          sync.Mutex
+	highlighted []Element
+}
+
+func NewInspector(r *Root) *Inspector {
+	return &Inspector{r: r}
+}
+
+// Highlight marks all element nodes of the VNode with HighlightAttr,
+// the previous highlighted will be unmarked.
+func (i *Inspector) Highlight(id string) error {
+	return i.highlight(id, true)
+}
+
+// ClearHighlight unmarks the highlighted nodes
+func (i *Inspector) ClearHighlight() error {
+	return i.highlight("", false)
+}
+
+func (i *Inspector) highlight(id string, mark bool) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var err error
+
+	if e := i.r.exclusive(func() {
+		// nodes may not be created until committed, so marks are committed with them
+		defer i.r.cq.ForceCommit()
+
+		for _, n := range i.highlighted {
+			i.r.removeAttribute(n, HighlightAttr)
+		}
+		i.highlighted = nil
+
+		if !mark {
+			return
+		}
+
+		vnode, e := i.r.vnodeOf(id)
+		if e != nil {
+			err = e
+			return
+		}
+
+		i.highlighted = elementNodesOf(vnode, i.highlighted)
+		for _, n := range i.highlighted {
+			i.r.setAttribute(n, HighlightAttr, "")
+		}
+	}); e != nil {
+		return e
+	}
+
+	return err
+}
+
+// elementNodesOf collects the top element nodes of vnode, including nodes in portals
+func elementNodesOf(vnode *VNode, nodes []Element) []Element {
+	if _, ok := vnode.Type.(internal.Element); ok && vnode.Node != nil {
+		return append(nodes, vnode.Node)
+	}
+
+	for i := range vnode.Children {
+		if child, ok := vnode.Children[i].(*VNode); ok {
+			nodes = elementNodesOf(child, nodes)
+		}
+	}
+	return nodes
+}
+
+// SetState sets the state of UseState by JSON, which is decoded as the type of the current state.
+func (i *Inspector) SetState(id string, hook int, state json.RawMessage) error {
+	var h *internal.StateHook
+	var t reflect.Type
+	var err error
+
+	if e := i.r.exclusive(func() {
+		vnode, e := i.r.vnodeOf(id)
+		if e != nil {
+			err = e
+			return
+		}
+
+		hooks := vnode.Hooks()
+		if hook < 0 || hook >= len(hooks) {
+			err = fmt.Errorf("%w: %s hook %d", ErrHookNotEditable, id, hook)
+			return
+		}
+
+		s, ok := hooks[hook].(*internal.StateHook)
+		if !ok {
+			err = fmt.Errorf("%w: %s hook %d", ErrHookNotEditable, id, hook)
+			return
+		}
+		h, t = s, reflect.TypeOf(s.State)
+	}); e != nil {
+		return e
+	}
+	if err != nil {
+		return err
+	}
+
+	var next interface{}
+
+	if t != nil {
+		v := reflect.New(t)
+		if err := json.Unmarshal(state, v.Interface()); err != nil {
+			return err
+		}
+		next = v.Elem().Interface()
+	} else if err := json.Unmarshal(state, &next); err != nil {
+		return err
+	}
+
+	// outside the render lock, SetState schedules the update
+	h.SetState(next)
+
+	return nil
+}
+
+// InspectRequest is the request to Inspector
+type InspectRequest struct {
+	// Seq is echoed in the response, to match the response of postMessage
+	Seq int `json:"seq,omitempty"`
+	// Action is one of "inspect", "highlight", "clearHighlight" and "setState"
+	Action string          `json:"action"`
+	ID     string          `json:"id,omitempty"`
+	Hook   int             `json:"hook,omitempty"`
+	State  json.RawMessage `json:"state,omitempty"`
+}
+
+// InspectResponse is the response of Inspector
+type InspectResponse struct {
+	Seq    int            `json:"seq,omitempty"`
+	Action string         `json:"action"`
+	Tree   *InspectedNode `json:"tree,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// Handle handles the request, the tree will be responded after highlighted or state set.
+func (i *Inspector) Handle(req InspectRequest) InspectResponse {
+	resp := InspectResponse{Seq: req.Seq, Action: req.Action}
+
+	var err error
+
+	switch req.Action {
+	case "inspect":
+	case "highlight":
+		err = i.Highlight(req.ID)
+	case "clearHighlight":
+		err = i.ClearHighlight()
+	case "setState":
+		if err = i.SetState(req.ID, req.Hook, req.State); err == nil {
+			i.r.FlushSync(nil)
+		}
+	default:
+		err = fmt.Errorf("renderer: unsupported inspect action %q", req.Action)
+	}
+
+	if err == nil {
+		resp.Tree, err = i.r.Inspect()
+	}
+
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	return resp
+}
+
+// ListenMessages handles InspectRequest in JSON posted to the window from origins,
+// and posts InspectResponse back, only the same origin allowed when origins empty.
+func (i *Inspector) ListenMessages(origins ...string) (stop func()) {
+	return browser.OnMessage(origins, func(data string, reply func(data string)) {
+		req := InspectRequest{}
+		if err := json.Unmarshal([]byte(data), &req); err != nil || req.Action == "" {
+			// not for the inspector
+			return
+		}
+
+		resp, _ := json.Marshal(i.Handle(req))
+		reply(string(resp))
+	})
+}
@@ -0,0 +1,152 @@
+package renderer_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type Editable struct {
+}
+
+func (Editable) Render(ctx context.Context, children ...interface{}) interface{} {
+	count, _ := UseState(ctx, 1)
+	label := UseMemo(ctx, func() string {
+		return "count"
+	}, []interface{}{})
+
+	return Div(Attr("title", label), Attr("onClick", func() {}), count)
+}
+
+type Expandable struct {
+	SetExpanded *SetStateFunc[bool]
+}
+
+func (e Expandable) Render(ctx context.Context, children ...interface{}) interface{} {
+	expanded, setExpanded := UseState(ctx, false)
+	*e.SetExpanded = setExpanded
+
+	if expanded {
+		return Div(B("more"))
+	}
+	return Div()
+}
+
+func TestInspector(t *testing.T) {
+	ctx := context.Background()
+
+	find := func(n *renderer.InspectedNode, name string) *renderer.InspectedNode {
+		var found *renderer.InspectedNode
+		var walk func(n *renderer.InspectedNode)
+		walk = func(n *renderer.InspectedNode) {
+			if found != nil {
+				return
+			}
+			if n.Name == name {
+				found = n
+				return
+			}
+			for _, c := range n.Children {
+				walk(c)
+			}
+		}
+		walk(n)
+		return found
+	}
+
+	root := Document.CreateElement("body")
+	r := renderer.CreateRoot(root)
+	defer r.Close()
+
+	_ = r.Render(ctx, Section(H(Editable{})(Key("editable"))))
+
+	i := renderer.NewInspector(r)
+
+	t.Run("should inspect the rendered tree", func(t *testing.T) {
+		tree, err := r.Inspect()
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		editable := find(tree, "Editable")
+		gomega.NewWithT(t).Expect(editable).NotTo(gomega.BeNil())
+		gomega.NewWithT(t).Expect(editable.Key).To(gomega.Equal("editable"))
+		gomega.NewWithT(t).Expect(editable.Hooks).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(editable.Hooks[0].Value).To(gomega.Equal("UseState: 1"))
+		gomega.NewWithT(t).Expect(string(editable.Hooks[0].State)).To(gomega.Equal("1"))
+		gomega.NewWithT(t).Expect(editable.Hooks[1].State).To(gomega.BeNil())
+
+		div := find(tree, "div")
+		gomega.NewWithT(t).Expect(div.Attrs).To(gomega.Equal(map[string]string{"title": "count", "onClick": "func"}))
+		gomega.NewWithT(t).Expect(div.Children[0].Text).To(gomega.Equal("1"))
+
+		_, err = json.Marshal(tree)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	})
+
+	t.Run("should highlight nodes of the selected VNode", func(t *testing.T) {
+		tree, _ := r.Inspect()
+
+		err := i.Highlight(find(tree, "Editable").ID)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><section><div data-gox-inspected="" title="count">1</div></section></body>`))
+
+		err = i.ClearHighlight()
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><section><div title="count">1</div></section></body>`))
+
+		err = i.Highlight("9-9")
+		gomega.NewWithT(t).Expect(errors.Is(err, renderer.ErrVNodeNotFound)).To(gomega.BeTrue())
+	})
+
+	t.Run("should highlight nodes not committed yet", func(t *testing.T) {
+		s := &renderer.ManualScheduler{}
+
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root, renderer.WithCommitScheduler(s))
+		defer r.Close()
+
+		setExpanded := new(SetStateFunc[bool])
+		_ = r.Render(ctx, H(Expandable{SetExpanded: setExpanded})())
+
+		r.RunWithPriority(renderer.UserBlockingPriority, func() {
+			(*setExpanded).Set(true)
+		})
+
+		var b *renderer.InspectedNode
+		gomega.NewWithT(t).Eventually(func() *renderer.InspectedNode {
+			tree, _ := r.Inspect()
+			b = find(tree, "b")
+			return b
+		}, time.Second).ShouldNot(gomega.BeNil())
+
+		err := renderer.NewInspector(r).Highlight(b.ID)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><b data-gox-inspected="">more</b></div></body>`))
+
+		s.Flush()
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><b data-gox-inspected="">more</b></div></body>`))
+	})
+
+	t.Run("should edit state in place", func(t *testing.T) {
+		tree, _ := r.Inspect()
+		id := find(tree, "Editable").ID
+
+		err := i.SetState(id, 0, json.RawMessage(`5`))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		r.FlushSync(nil)
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><section><div title="count">5</div></section></body>`))
+
+		err = i.SetState(id, 0, json.RawMessage(`"5"`))
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+
+		err = i.SetState(id, 1, json.RawMessage(`"x"`))
+		gomega.NewWithT(t).Expect(errors.Is(err, renderer.ErrHookNotEditable)).To(gomega.BeTrue())
+	})
+}
@@ -0,0 +1,162 @@
+package renderer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/onsi/gomega"
+)
+
+func Test_longestIncreasingSubsequence(t *testing.T) {
+	cases := []struct {
+		values []int
+		seq    []int
+	}{
+		{nil, []int{}},
+		{[]int{1, 2, 3}, []int{0, 1, 2}},
+		{[]int{3, 2, 1}, []int{2}},
+		{[]int{2, 0, 3, 1, 4}, []int{0, 2, 4}},
+		{[]int{5, 1, 0, 2, 3}, []int{1, 3, 4}},
+	}
+
+	for _, c := range cases {
+		gomega.NewWithT(t).Expect(longestIncreasingSubsequence(c.values)).To(gomega.Equal(c.seq))
+	}
+}
+
+// movesCounter counts nodes inserted into the parent
+type movesCounter struct {
+	Element
+	inserted int
+}
+
+func (c *movesCounter) InsertBefore(newNode, ref Node) Node {
+	c.inserted++
+	return c.Element.InsertBefore(newNode, ref)
+}
+
+func (c *movesCounter) AppendChild(n Node) {
+	c.inserted++
+	c.Element.AppendChild(n)
+}
+
+func TestKeyedChildren(t *testing.T) {
+	ctx := context.Background()
+
+	keyed := func(keys ...int) *VNode {
+		children := make([]interface{}, len(keys))
+		for i, k := range keys {
+			children[i] = Li(Key(fmt.Sprint(k)), fmt.Sprint(k))
+		}
+		return Fragment(children...)
+	}
+
+	html := func(keys ...int) string {
+		buf := bytes.NewBufferString("<ul>")
+		for _, k := range keys {
+			_, _ = fmt.Fprintf(buf, "<li>%d</li>", k)
+		}
+		buf.WriteString("</ul>")
+		return buf.String()
+	}
+
+	seq := func(n int) []int {
+		keys := make([]int, n)
+		for i := range keys {
+			keys[i] = i
+		}
+		return keys
+	}
+
+	cases := []struct {
+		name  string
+		from  []int
+		to    []int
+		moves int
+	}{
+		{"move last to first", seq(10), append([]int{9}, seq(9)...), 1},
+		{"move first to last", seq(10), append(seq(10)[1:], 0), 1},
+		{"swap", []int{0, 1, 2, 3, 4}, []int{0, 3, 2, 1, 4}, 2},
+		{"reverse", seq(5), []int{4, 3, 2, 1, 0}, 4},
+		{"insert middle", []int{0, 1, 3, 4}, []int{0, 1, 2, 3, 4}, 1},
+		{"remove middle", []int{0, 1, 2, 3, 4}, []int{0, 1, 3, 4}, 0},
+		{"replace middle", []int{0, 1, 2, 3}, []int{0, 5, 6, 3}, 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ul := &movesCounter{Element: Document.CreateElement("ul")}
+			r := CreateRoot(ul)
+			defer r.Close()
+
+			_ = r.Render(ctx, keyed(c.from...))
+
+			nodes := map[string]Node{}
+			for n := ul.FirstChild(); n != nil; n = n.NextSibling() {
+				nodes[n.FirstChild().TextContent()] = n
+			}
+
+			ul.inserted = 0
+			_ = r.Render(ctx, keyed(c.to...))
+
+			buf := bytes.NewBuffer(nil)
+			RenderToHTML(buf, ul.Element)
+
+			gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(html(c.to...)))
+			gomega.NewWithT(t).Expect(ul.inserted).To(gomega.Equal(c.moves))
+
+			for n := ul.FirstChild(); n != nil; n = n.NextSibling() {
+				if old, ok := nodes[n.FirstChild().TextContent()]; ok {
+					gomega.NewWithT(t).Expect(n == old).To(gomega.BeTrue())
+				}
+			}
+		})
+	}
+
+	t.Run("shuffle", func(t *testing.T) {
+		ul := Document.CreateElement("ul")
+		r := CreateRoot(ul)
+		defer r.Close()
+
+		keys := seq(50)
+		_ = r.Render(ctx, keyed(keys...))
+
+		seed := int64(1)
+		t.Logf("seed: %d", seed)
+		rnd := rand.New(rand.NewSource(seed))
+
+		for i := 0; i < 20; i++ {
+			next := append([]int{}, keys[:rnd.Intn(len(keys))]...)
+			next = append(next, 50+i)
+			rnd.Shuffle(len(next), func(i, j int) {
+				next[i], next[j] = next[j], next[i]
+			})
+
+			_ = r.Render(ctx, keyed(next...))
+
+			buf := bytes.NewBuffer(nil)
+			RenderToHTML(buf, ul)
+			gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(html(next...)))
+
+			keys = next
+		}
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		ul := Document.CreateElement("ul")
+		r := CreateRoot(ul)
+		defer r.Close()
+
+		_ = r.Render(ctx, keyed(0, 1, 1, 2))
+		_ = r.Render(ctx, keyed(2, 1, 1, 0))
+
+		buf := bytes.NewBuffer(nil)
+		RenderToHTML(buf, ul)
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(html(2, 1, 1, 0)))
+	})
+}
@@ -0,0 +1,242 @@
+package renderer
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-courier/gox/pkg/gox/internal"
+
+	. "github.com/go-courier/gox/pkg/gox"
+)
+
+// RenderReason tells why a component rendered, reasons of coalesced updates are combined.
+type RenderReason int
+
+const (
+	// RenderByMount when the component rendered first time
+	RenderByMount RenderReason = 1 << iota
+	// RenderByParent when the parent rendered
+	RenderByParent
+	// RenderByState when states of its hooks changed
+	RenderByState
+	// RenderByContext when values of subscribed contexts changed
+	RenderByContext
+)
+
+var renderReasonNames = []string{"mount", "parent", "state", "context"}
+
+func (reason RenderReason) String() string {
+	names := make([]string, 0, 1)
+	for i, name := range renderReasonNames {
+		if reason&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+func renderReasonOf(reason internal.UpdateReason) RenderReason {
+	if reason == internal.UpdateByContext {
+		return RenderByContext
+	}
+	return RenderByState
+}
+
+// RenderRecord of a rendered component
+type RenderRecord struct {
+	// Name of the component
+	Name   string
+	Key    string
+	Reason RenderReason
+	Start  time.Time
+	// Duration of rendering the component with descendants
+	Duration time.Duration
+	// SelfDuration of the Render of the component
+	SelfDuration time.Duration
+	// Hooks is the count of hook calls
+	Hooks int
+	// Operations counts DOM mutations queued by the component itself like insertBefore,
+	// the ones of descendant components are counted in their own records.
+	Operations map[string]int
+}
+
+// CommitRecord of a committed phase
+type CommitRecord struct {
+	Phase    CommitPhase
+	Start    time.Time
+	Duration time.Duration
+	// Count of the functions run
+	Count int
+	// Operations counts DOM mutations by name like insertBefore, only for MutationPhase
+	Operations map[string]int
+}
+
+// Tracer receives records of renders and commits.
+// TraceRender is called with the render lock held, and TraceCommit is called when phases committed,
+// which may be in callbacks of the CommitScheduler without the render lock,
+// so methods should be safe for concurrent use, and return quickly.
+type Tracer interface {
+	TraceRender(record RenderRecord)
+	TraceCommit(record CommitRecord)
+}
+
+// WithTracer installs t to trace renders and commits of the Root
+func WithTracer(t Tracer) RootOption {
+	return func(r *Root) {
+		r.tracer = t
+		r.cq.tracer = t
+	}
+}
+
+// TraceRecorder is the Tracer keeps all records, which could be exported as Chrome trace events.
+type TraceRecorder struct {
+	mu      sync.Mutex
+	renders []RenderRecord
+	commits []CommitRecord
+}
+
+func (t *TraceRecorder) TraceRender(record RenderRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.renders = append(t.renders, record)
+}
+
+func (t *TraceRecorder) TraceCommit(record CommitRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.commits = append(t.commits, record)
+}
+
+// Renders returns all recorded renders
+func (t *TraceRecorder) Renders() []RenderRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]RenderRecord(nil), t.renders...)
+}
+
+// Commits returns all recorded commits
+func (t *TraceRecorder) Commits() []CommitRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]CommitRecord(nil), t.commits...)
+}
+
+// traceEvent https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type traceEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat"`
+	Ph   string                 `json:"ph"`
+	Ts   int64                  `json:"ts"`
+	Dur  int64                  `json:"dur"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// WriteChromeTrace writes records as Chrome trace-event JSON,
+// which could be loaded by chrome://tracing or Perfetto.
+func (t *TraceRecorder) WriteChromeTrace(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := make([]traceEvent, 0, len(t.renders)+len(t.commits))
+
+	var origin time.Time
+	for _, r := range t.renders {
+		if origin.IsZero() || r.Start.Before(origin) {
+			origin = r.Start
+		}
+	}
+	for _, c := range t.commits {
+		if origin.IsZero() || c.Start.Before(origin) {
+			origin = c.Start
+		}
+	}
+
+	for _, r := range t.renders {
+		args := map[string]interface{}{
+			"reason":       r.Reason.String(),
+			"hooks":        r.Hooks,
+			"selfDuration": r.SelfDuration.Microseconds(),
+		}
+		if r.Key != "" {
+			args["key"] = r.Key
+		}
+		for op, n := range r.Operations {
+			args[op] = n
+		}
+
+		events = append(events, traceEvent{
+			Name: r.Name,
+			Cat:  "render",
+			Ph:   "X",
+			Ts:   r.Start.Sub(origin).Microseconds(),
+			Dur:  r.Duration.Microseconds(),
+			Pid:  1,
+			Tid:  1,
+			Args: args,
+		})
+	}
+
+	phaseNames := map[CommitPhase]string{
+		MutationPhase: "mutation",
+		LayoutPhase:   "layout",
+		PassivePhase:  "passive",
+	}
+
+	for _, c := range t.commits {
+		args := map[string]interface{}{
+			"count": c.Count,
+		}
+		for op, n := range c.Operations {
+			args[op] = n
+		}
+
+		events = append(events, traceEvent{
+			Name: "commit " + phaseNames[c.Phase],
+			Cat:  "commit",
+			Ph:   "X",
+			Ts:   c.Start.Sub(origin).Microseconds(),
+			Dur:  c.Duration.Microseconds(),
+			Pid:  1,
+			Tid:  2,
+			Args: args,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Ts < events[j].Ts
+	})
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	})
+}
+
+// profile reports the update of vnode to all Profilers from vnode up to the root after committed
+func (r *Root) profile(vnode *VNode, start time.Time, d time.Duration) {
+	for v := vnode; v != nil; v = v.Parent {
+		if p, ok := v.Type.(internal.Profiler); ok {
+			r.reportProfile(p, internal.ProfileInfo{ID: p.ID, Phase: "update", StartTime: start, ActualDuration: d})
+		}
+	}
+}
+
+func (r *Root) reportProfile(p internal.Profiler, info internal.ProfileInfo) {
+	if p.OnRender == nil {
+		return
+	}
+
+	r.cq.DispatchLayout(func() {
+		p.OnRender(info)
+	})
+}
@@ -0,0 +1,191 @@
+package renderer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-courier/gox/pkg/gox/internal"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+)
+
+// domProperty is the attr set as DOM property, as the attribute of it only sets the default,
+// or it is not an attribute at all.
+type domProperty struct {
+	// tags of elements have the property, all elements when nil
+	tags map[string]bool
+	// zero is set when the attr removed
+	zero interface{}
+	// live property could be changed by user, like value of input,
+	// synced in every patching, to keep form inputs controlled.
+	live bool
+}
+
+func tagSet(tags ...string) map[string]bool {
+	m := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		m[tag] = true
+	}
+	return m
+}
+
+var domProperties = map[string]domProperty{
+	"value":         {tags: tagSet("input", "textarea", "select"), zero: "", live: true},
+	"checked":       {tags: tagSet("input"), zero: false, live: true},
+	"indeterminate": {tags: tagSet("input"), zero: false, live: true},
+	"selected":      {tags: tagSet("option"), zero: false, live: true},
+	"muted":         {tags: tagSet("audio", "video"), zero: false},
+	// not escaped, should only be used for trusted html
+	"innerHTML": {zero: ""},
+	"className": {zero: ""},
+}
+
+// propertyOf returns the DOM property of the attr key of vnode
+func propertyOf(vnode *VNode, key string) (domProperty, bool) {
+	p, ok := domProperties[key]
+	if !ok {
+		return p, false
+	}
+	if p.tags != nil {
+		tag, _ := vnode.Type.(internal.Element)
+		return p, p.tags[strings.ToLower(string(tag))]
+	}
+	return p, true
+}
+
+// isLiveProperty returns true when the attr key of vnode should be synced even not changed
+func isLiveProperty(vnode *VNode, key string) bool {
+	p, ok := propertyOf(vnode, key)
+	return ok && p.live
+}
+
+// isSetAfterChildren returns true when the attr key of vnode should be set after children mounted,
+// value of select only selects the option mounted.
+func isSetAfterChildren(vnode *VNode, key string) bool {
+	if key != "value" {
+		return false
+	}
+	tag, _ := vnode.Type.(internal.Element)
+	return strings.ToLower(string(tag)) == "select"
+}
+
+// isEnumeratedAttr returns true when booleans of the attribute are written as "true" or "false"
+func isEnumeratedAttr(key string) bool {
+	return strings.HasPrefix(key, "aria-") || strings.HasPrefix(key, "data-")
+}
+
+// setAttr sets v of key as DOM property or attribute,
+// boolean attribute is removed for false, and attribute is removed for nil.
+func (r *Root) setAttr(vnode *VNode, key string, v interface{}, existed bool) {
+	if p, ok := propertyOf(vnode, key); ok {
+		if v == nil {
+			v = p.zero
+		}
+		if p.live {
+			r.syncProperty(vnode.Node, key, v)
+		} else {
+			r.setProperty(vnode.Node, key, v)
+		}
+		return
+	}
+
+	switch x := v.(type) {
+	case nil:
+		if existed {
+			r.removeAttribute(vnode.Node, key)
+		}
+	case bool:
+		if isEnumeratedAttr(key) {
+			r.setAttribute(vnode.Node, key, strconv.FormatBool(x))
+		} else if x {
+			r.setAttribute(vnode.Node, key, "")
+		} else if existed {
+			r.removeAttribute(vnode.Node, key)
+		}
+	default:
+		r.setAttribute(vnode.Node, key, v)
+	}
+}
+
+// removeAttr removes the DOM property or attribute of key
+func (r *Root) removeAttr(vnode *VNode, key string) {
+	if p, ok := propertyOf(vnode, key); ok {
+		r.setProperty(vnode.Node, key, p.zero)
+		return
+	}
+	r.removeAttribute(vnode.Node, key)
+}
+
+func (r *Root) setProperty(node Element, k string, v interface{}) {
+	r.cq.Dispatch("setProperty", func() {
+		node.Set(k, v)
+	})
+}
+
+// syncProperty sets the property only when the live value differs
+func (r *Root) syncProperty(node Element, k string, v interface{}) {
+	r.cq.Dispatch("setProperty", func() {
+		if !internal.Identical(node.Get(k), v) {
+			node.Set(k, v)
+		}
+	})
+}
+
+// patchStyle updates changed properties only, when style is a map like css.CSS
+func (r *Root) patchStyle(vnode *VNode, old interface{}, cur interface{}, existed bool) {
+	oldStyle, oldIsMap := StyleMapOf(old)
+	style, isMap := StyleMapOf(cur)
+
+	// diff by property names, fontSize and font-size are the same property
+	oldStyle, style = stylePropertiesOf(oldStyle), stylePropertiesOf(style)
+
+	if !isMap {
+		if existed && !oldIsMap && internal.Identical(old, cur) {
+			return
+		}
+		r.setAttr(vnode, "style", cur, existed)
+		return
+	}
+
+	if existed && !oldIsMap {
+		// whole style string replaced by properties
+		r.removeAttribute(vnode.Node, "style")
+	}
+
+	for property, v := range style {
+		if o, ok := oldStyle[property]; ok && internal.Identical(o, v) {
+			continue
+		}
+		r.setStyleProperty(vnode.Node, property, v)
+	}
+
+	for property := range oldStyle {
+		if _, ok := style[property]; !ok {
+			r.setStyleProperty(vnode.Node, property, nil)
+		}
+	}
+}
+
+// stylePropertiesOf returns values of style by css property names
+func stylePropertiesOf(style map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, len(style))
+	for name, v := range style {
+		properties[CSSPropertyName(name)] = v
+	}
+	return properties
+}
+
+// setStyleProperty sets the style property, removes it when the value is empty
+func (r *Root) setStyleProperty(node Element, property string, v interface{}) {
+	if value, ok := CSSValue(property, v); ok {
+		r.cq.Dispatch("setStyleProperty", func() {
+			node.Style().SetProperty(property, value)
+		})
+		return
+	}
+
+	r.cq.Dispatch("removeStyleProperty", func() {
+		node.Style().RemoveProperty(property)
+	})
+}
@@ -1,20 +1,319 @@
 package renderer
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-courier/gox/pkg/gox/internal"
 
 	. "github.com/go-courier/gox/pkg/dom"
-	"github.com/go-courier/gox/pkg/gox"
+	. "github.com/go-courier/gox/pkg/gox"
+)
+
+// hydration markers written as html comments by RenderToString
+const (
+	// markerComponentStart opens a component, followed by the key of the component if exists,
+	// and markerIds with ids generated by UseId in the component
+	markerComponentStart = "["
+	// markerIds splits the key and ids in the start marker of component
+	markerIds = "#"
+	// markerComponentEnd closes a component
+	markerComponentEnd = "]"
+	// markerTextSeparator splits adjacent text nodes, which would be merged by the html parser
+	markerTextSeparator = "|"
+	// attrKey holds the key of element
+	attrKey = "data-gox-key"
 )
 
-func RenderToString(ctx context.Context, w io.Writer, vnode *gox.VNode) error {
-	doc := Document.CreateElement("html")
+// RenderToString streams html of vnode into w.
+// Html is written as each VNode subtree finishes rendering,
+// and flushed to the client when a Boundary completes.
+func RenderToString(ctx context.Context, w io.Writer, vnode *VNode) error {
+	s := &streamRenderer{
+		dest: w,
+		w:    bufio.NewWriter(w),
+		ids:  internal.IdCounter{Prefix: "gox-s"},
+	}
+
+	// hooks like resources of Suspense are scoped in this render
+	defer s.destroy()
+
+	s.renderVNode(ctx, Portal(nil)(vnode))
+
+	return s.flush()
+}
+
+type streamRenderer struct {
+	dest io.Writer
+	w    *bufio.Writer
+	// to insert separator between adjacent text nodes
+	lastIsText bool
+	// VNodes being rendered
+	stack vnodeStack
+	// ids of UseId, written in markers to be taken over by hydration
+	ids internal.IdCounter
+	// components rendered, destroyed once finished
+	rendered []*VNode
+}
+
+// destroy releases hooks of rendered components,
+// pending resources of Suspense are canceled, and the cached ones are dropped.
+func (s *streamRenderer) destroy() {
+	for _, vnode := range s.rendered {
+		_ = vnode.Destroy()
+	}
+	s.rendered = nil
+}
 
-	if err := CreateRoot(doc).Render(ctx, vnode); err != nil {
+func (s *streamRenderer) flush() error {
+	if err := s.w.Flush(); err != nil {
 		return err
 	}
 
-	RenderToHTML(w, doc)
+	switch f := s.dest.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		// http.Flusher
+		f.Flush()
+	}
+
 	return nil
 }
+
+func (s *streamRenderer) renderChildren(ctx context.Context, vnode *VNode) {
+	for i := range vnode.Children {
+		if vn, ok := vnode.Children[i].(*VNode); ok {
+			s.renderVNode(ctx, vn)
+		}
+	}
+}
+
+func (s *streamRenderer) renderVNode(ctx context.Context, vnode *VNode) {
+	// not deferred, to keep the panicked one on top
+	s.stack.push(vnode)
+
+	switch x := vnode.Type.(type) {
+	case internal.Text:
+		if s.lastIsText {
+			WriteComment(s.w, markerTextSeparator)
+		}
+		if IsRawTextElement(parentTagOf(vnode)) {
+			WriteRawText(s.w, string(x))
+		} else {
+			WriteText(s.w, string(x))
+		}
+		s.lastIsText = true
+	case internal.Element:
+		walkChildren(ctx, vnode, vnode.InputChildren...)
+
+		attrs, content, hasContent := htmlAttrsOf(vnode)
+
+		WriteStartTag(s.w, string(x), attrs)
+		s.lastIsText = false
+
+		if hasContent {
+			_, _ = io.WriteString(s.w, content)
+		} else {
+			s.renderChildren(ctx, vnode)
+		}
+
+		WriteEndTag(s.w, string(x))
+		s.lastIsText = false
+	case internal.Fragment:
+		// portal target is not a part of the streamed html
+		if !(vnode.IsRoot && vnode.Node != nil) {
+			walkChildren(ctx, vnode, vnode.InputChildren...)
+			s.renderChildren(ctx, vnode)
+		}
+	default:
+		if isBoundary(vnode) {
+			s.renderBoundary(ctx, vnode)
+		} else {
+			s.renderComponent(ctx, vnode)
+		}
+	}
+
+	s.stack.pop()
+}
+
+// htmlAttrsOf returns attributes of vnode written in html, event handlers are skipped, DOM properties are converted to attributes,
+// and the html of innerHTML, or the escaped value of textarea is returned as the content.
+func htmlAttrsOf(vnode *VNode) (attrs Attrs, content string, ok bool) {
+	attrs = Attrs{}
+	attrs.Merge(vnode.Attrs)
+	if vnode.Key != "" {
+		attrs[attrKey] = string(vnode.Key)
+	}
+
+	for key, v := range attrs {
+		if _, _, isEvent := parseEventAttr(key); isEvent {
+			if _, isHandler := toEventHandler(v); isHandler {
+				// bound when hydrating
+				delete(attrs, key)
+				continue
+			}
+		}
+		if b, isBool := v.(bool); isBool && isEnumeratedAttr(key) {
+			attrs[key] = strconv.FormatBool(b)
+		}
+	}
+
+	if c, exists := attrs["className"]; exists {
+		delete(attrs, "className")
+		if _, exists := attrs["class"]; !exists {
+			attrs["class"] = c
+		}
+	}
+
+	if html, exists := attrs["innerHTML"]; exists {
+		delete(attrs, "innerHTML")
+		if html != nil {
+			return attrs, fmt.Sprint(html), true
+		}
+	}
+
+	if tag, _ := vnode.Type.(internal.Element); strings.ToLower(string(tag)) == "textarea" {
+		if value, exists := attrs["value"]; exists {
+			delete(attrs, "value")
+			if value != nil {
+				b := &strings.Builder{}
+				WriteText(b, fmt.Sprint(value))
+				return attrs, b.String(), true
+			}
+		}
+	}
+
+	return attrs, "", false
+}
+
+// parentTagOf returns the tag name of the nearest element above vnode
+func parentTagOf(vnode *VNode) string {
+	for p := vnode.Parent; p != nil; p = p.Parent {
+		if e, ok := p.Type.(internal.Element); ok {
+			return string(e)
+		}
+	}
+	return ""
+}
+
+func (s *streamRenderer) renderComponent(ctx context.Context, vnode *VNode) {
+	vnode.WillRender(nil)
+	s.rendered = append(s.rendered, vnode)
+
+	childCtx := ctx
+
+	if cp, ok := vnode.Type.(internal.ContextProvider); ok {
+		childCtx = cp.GetChildContext(internal.ContextWithVNode(ctx, vnode))
+	}
+
+	ids := &internal.IdSequence{Generator: &s.ids}
+	renderCtx := internal.ContextWithIdGenerator(internal.ContextWithVNode(childCtx, vnode), ids)
+
+	walkChildren(childCtx, vnode, internal.JSX(internal.Fragment{}, vnode.Type.Render(renderCtx, vnode.InputChildren...)))
+
+	WriteComment(s.w, componentStartMarker(vnode.Key, ids.Ids))
+	s.lastIsText = false
+
+	s.renderChildren(childCtx, vnode)
+
+	WriteComment(s.w, markerComponentEnd)
+	s.lastIsText = false
+
+	if _, ok := vnode.Type.(internal.FlushBoundary); ok {
+		_ = s.flush()
+	}
+}
+
+// componentStartMarker returns the start marker of component with key and ids.
+// ids never contain markerIds, so markerIds is always written when key contains it.
+func componentStartMarker(key Key, ids []string) string {
+	marker := markerComponentStart + string(key)
+	if len(ids) > 0 || strings.Contains(string(key), markerIds) {
+		marker += markerIds + strings.Join(ids, ",")
+	}
+	return marker
+}
+
+// idsOfComponentStartMarker returns ids in the start marker of component
+func idsOfComponentStartMarker(marker string) []string {
+	i := strings.LastIndex(marker, markerIds)
+	if i < 0 || i == len(marker)-1 {
+		return nil
+	}
+	return strings.Split(marker[i+1:], ",")
+}
+
+// renderBoundary buffers the html of the ErrorBoundary or Suspense,
+// so the fallback could be written instead once descendants panicked.
+// For Suspense, html rendered before is flushed, then renders again once the resource resolved.
+// Boundaries inside are flushed with the whole boundary.
+func (s *streamRenderer) renderBoundary(ctx context.Context, vnode *VNode) {
+	for {
+		html, p := s.tryRenderComponent(ctx, vnode)
+
+		if p == nil {
+			_, _ = s.w.Write(html)
+			return
+		}
+
+		suspended := &internal.Suspended{}
+
+		if _, ok := vnode.Type.(internal.Suspense); ok && errors.As(p, &suspended) {
+			// let the client get html before as soon as possible
+			_ = s.flush()
+
+			select {
+			case <-suspended.Done:
+				continue
+			case <-ctx.Done():
+				// render fallback when canceled
+			}
+		}
+
+		if !catchAt(vnode, p) {
+			panic(p)
+		}
+
+		s.renderComponent(ctx, vnode)
+		return
+	}
+}
+
+func (s *streamRenderer) tryRenderComponent(ctx context.Context, vnode *VNode) (html []byte, p *RenderPanic) {
+	dest, w, lastIsText := s.dest, s.w, s.lastIsText
+
+	buf := bytes.NewBuffer(nil)
+	s.dest, s.w = buf, bufio.NewWriter(buf)
+
+	depth := len(s.stack)
+
+	defer func() {
+		if e := recover(); e != nil {
+			origin := vnode
+			if len(s.stack) > depth {
+				origin = s.stack[len(s.stack)-1]
+			}
+			p = newRenderPanic(e, origin)
+			s.lastIsText = lastIsText
+		}
+
+		_ = s.w.Flush()
+		s.dest, s.w = dest, w
+		s.stack = s.stack[:depth]
+
+		if p == nil {
+			html = buf.Bytes()
+		}
+	}()
+
+	s.renderComponent(ctx, vnode)
+
+	return nil, nil
+}
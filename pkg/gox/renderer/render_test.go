@@ -0,0 +1,91 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type flushRecorder struct {
+	bytes.Buffer
+	flushed []string
+	// called after the first flush
+	onFlush func()
+}
+
+func (w *flushRecorder) Flush() {
+	w.flushed = append(w.flushed, w.String())
+	if len(w.flushed) == 1 && w.onFlush != nil {
+		w.onFlush()
+	}
+}
+
+func TestRenderToString(t *testing.T) {
+	t.Run("should write hydration markers", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+
+		err := renderer.RenderToString(context.Background(), buf, Div(
+			Attrs{"role": "value"},
+			H(App{Text: "app"})(Key("app")),
+			Ul(
+				Li(Key("1"), "1"),
+				Li(Key("2"), "2"),
+			),
+			"a", "b",
+		))
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(
+			`<div role="value"><!--[app--><div>hello <!--[-->app<!--]--></div><!--]--><ul><li data-gox-key="1">1</li><li data-gox-key="2">2</li></ul>a<!--|-->b</div>`,
+		))
+	})
+
+	t.Run("should escape text and attributes", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+
+		err := renderer.RenderToString(context.Background(), buf, Div(
+			Attrs{"title": `"><script>alert(1)</script>`, "hidden": false},
+			"<script>alert(1)</script>",
+			Input(Attrs{"disabled": true}),
+			Script(Fragment("if (a < b && c) {}")),
+		))
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(
+			`<div title="&quot;><script>alert(1)</script>">&lt;script&gt;alert(1)&lt;/script&gt;<input disabled=""><script>if (a < b && c) {}</script></div>`,
+		))
+	})
+
+	t.Run("should skip event handlers", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+
+		err := renderer.RenderToString(context.Background(), buf, Button(
+			Attrs{"onClick": func() {}, "onFocusCapture": func(e dom.Event) {}, "onion": "1"},
+			"click",
+		))
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(`<button onion="1">click</button>`))
+	})
+
+	t.Run("should flush when boundary completed", func(t *testing.T) {
+		w := &flushRecorder{}
+
+		err := renderer.RenderToString(context.Background(), w, Div(
+			Boundary(Span("1")),
+			Boundary(Span("2")),
+		))
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(w.flushed).To(gomega.Equal([]string{
+			`<div><!--[--><span>1</span><!--]-->`,
+			`<div><!--[--><span>1</span><!--]--><!--[--><span>2</span><!--]-->`,
+			`<div><!--[--><span>1</span><!--]--><!--[--><span>2</span><!--]--></div>`,
+		}))
+	})
+}
@@ -2,7 +2,12 @@ package renderer
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-courier/gox/pkg/gox/internal"
 
@@ -10,106 +15,373 @@ import (
 	. "github.com/go-courier/gox/pkg/gox"
 )
 
-func CreateRoot(root Element) *Root {
+func CreateRoot(root Element, options ...RootOption) *Root {
 	r := &Root{
 		doc:  root.OwnerDocument(),
 		root: Portal(root)(),
+		done: make(chan struct{}),
+		ids:  internal.IdCounter{Prefix: "gox-" + strconv.FormatUint(uint64(atomic.AddUint32(&rootCount, 1)), 10) + "-"},
 	}
-	r.cq.Start()
+	for _, option := range options {
+		option(r)
+	}
+	r.scheduler.wake = make(chan struct{}, 1)
+	go r.loop()
 	return r
 }
 
+// count of created roots, to keep ids of UseId unique between roots
+var rootCount uint32
+
+// RootOption configures the Root
+type RootOption func(r *Root)
+
+// WithCommitScheduler sets the CommitScheduler of updates,
+// commits of Render, FlushSync and Unmount are always synchronous.
+func WithCommitScheduler(s CommitScheduler) RootOption {
+	return func(r *Root) {
+		r.cq.scheduler = s
+	}
+}
+
+// ErrRootClosed returned when rendering on a closed Root
+var ErrRootClosed = errors.New("renderer: root closed")
+
+// ErrRootUnmounted returned when rendering on an unmounted Root
+var ErrRootUnmounted = errors.New("renderer: root unmounted")
+
 type Root struct {
 	cq   commitQueue
 	doc  Doc
 	root *VNode
+	// delegated event handlers of onX attrs
+	events eventDelegator
+	// only set while hydrating
+	hydration *hydration
+	// state updates scheduled by components
+	scheduler scheduler
+
+	// serializes renders and commits,
+	// VNodes and hooks are only mutated with it held.
+	mu        sync.Mutex
+	closed    bool
+	unmounted bool
+	done      chan struct{}
+	// VNodes being rendered
+	stack vnodeStack
+	// receives records of renders when set
+	tracer Tracer
+	// ids of UseId, generated at mount
+	ids internal.IdCounter
+	// nodes of the patching Fragment or component are placed before it,
+	// nil means appended to the mounted node.
+	before Element
 }
 
+// Close stops the render loop, pending updates are dropped.
+// Close waits for the in-flight render, so it must not be called in render or effects.
 func (r *Root) Close() error {
-	return r.cq.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.close()
+	return nil
 }
 
-func (r *Root) Render(ctx context.Context, vnode *VNode) error {
-	nextRoot := Portal(r.root.Node)(vnode)
-	r.patchVNode(ctx, r.root, nextRoot)
-	r.root = nextRoot
+func (r *Root) close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	close(r.done)
+	r.scheduler.close()
+}
+
+// Unmount removes all nodes rendered, destroys all VNodes with effects cleaned up children first,
+// releases the event listeners on mount points, then closes the Root.
+// Unmount waits for the in-flight render, so it must not be called in render or effects.
+func (r *Root) Unmount() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.unmounted {
+		return nil
+	}
+	r.unmounted = true
+
+	r.removeVNode(nil, r.root)
 	r.cq.ForceCommit()
+	r.removeEventListeners()
+
+	r.close()
 	return nil
 }
 
-func (r *Root) Act(fn func()) {
+// Metrics returns metrics of commits
+func (r *Root) Metrics() Metrics {
+	return r.cq.Metrics()
+}
+
+// exclusive runs fn with the render lock held.
+func (r *Root) exclusive(fn func()) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.unmounted {
+		return ErrRootUnmounted
+	}
+	if r.closed {
+		return ErrRootClosed
+	}
 	fn()
-	r.cq.ForceCommit()
+	return nil
+}
+
+// Render patches the tree by vnode,
+// returns *RenderPanic when rendering panicked and no ErrorBoundary caught it.
+func (r *Root) Render(ctx context.Context, vnode *VNode) error {
+	var p *RenderPanic
+
+	if err := r.exclusive(func() {
+		nextRoot := Portal(r.root.Node)(vnode)
+
+		if p = r.recoverRender(nextRoot, func() {
+			r.patchVNode(ctx, r.root, nextRoot)
+			r.flushContextUpdates()
+		}); p != nil {
+			return
+		}
+
+		r.root = nextRoot
+		r.cq.ForceCommit()
+	}); err != nil {
+		return err
+	}
+
+	if p != nil {
+		return p
+	}
+	return nil
+}
+
+// Act runs fn, then renders all pending updates and commits them.
+func (r *Root) Act(fn func()) {
+	r.FlushSync(fn)
 }
 
 func (r *Root) sameVNode(vnode1 *VNode, vnode2 *VNode) bool {
 	return internal.SameComponent(vnode1.Type, vnode2.Type) && vnode1.Key == vnode2.Key
 }
 
-func (r *Root) patchVNode(ctx context.Context, oldVNode *VNode, vnode *VNode) {
+// shouldReuse returns true when the component of vnode is not changed
+func shouldReuse(oldVNode *VNode, vnode *VNode) bool {
+	if su, ok := vnode.Type.(internal.ShouldUpdate); ok && !oldVNode.Stale() {
+		return !su.ShouldUpdate(oldVNode.Type) && internal.ShallowEqual(oldVNode.InputChildren, vnode.InputChildren)
+	}
+	return false
+}
+
+// patchVNode patches oldVNode to vnode, and returns the VNode should be kept in the tree,
+// which will be oldVNode when the component could be reused.
+func (r *Root) patchVNode(ctx context.Context, oldVNode *VNode, vnode *VNode) *VNode {
 	if vnode == oldVNode {
-		return
+		return vnode
+	}
+
+	if oldVNode != nil && shouldReuse(oldVNode, vnode) {
+		oldVNode.Parent = vnode.Parent
+		return oldVNode
 	}
 
+	// not deferred, to keep the panicked one on top
+	r.stack.push(vnode)
+
 	switch vnode.Type.(type) {
 	case internal.Text:
 		r.mount(ctx, oldVNode, vnode)
-		r.cq.Dispatch(func() {
-			vnode.DidMount()
-		})
+		r.didMount(vnode)
 	case internal.Element, internal.Fragment:
 		walkChildren(ctx, vnode, vnode.InputChildren...)
 		r.mount(ctx, oldVNode, vnode)
-		r.cq.Dispatch(func() {
-			vnode.DidMount()
-		})
+		r.didMount(vnode)
 	default:
 		// only component need to render
-		var doRender func(ctx context.Context, oldVNode *VNode, vnode *VNode)
+		// snapshot of last rendered, as the old VNode of next update
+		var rendered VNode
+		// duration of Render of the component, only measured when tracing
+		var selfDuration time.Duration
+		// ids generated on server side, taken over by UseId
+		var hydratedIds []string
+		if h := r.hydration; h != nil && oldVNode == nil {
+			hydratedIds = h.claimIds()
+		}
+
+		render := func(oldVNode *VNode) {
+			vnode.WillRender(oldVNode)
 
-		doRender = func(ctx context.Context, oldVNode *VNode, vnode *VNode) {
 			childCtx := ctx
 
 			if cp, ok := vnode.Type.(internal.ContextProvider); ok {
-				childCtx = cp.GetChildContext(ctx)
+				// with the VNode, providers could use hooks to keep states between renders
+				childCtx = cp.GetChildContext(internal.ContextWithVNode(ctx, vnode))
 			}
 
-			vnode.WillRender(oldVNode)
+			start := time.Time{}
+			if r.tracer != nil {
+				start = time.Now()
+			}
+
+			ids := internal.IdGenerator(&r.ids)
+			if hydratedIds != nil {
+				ids = &internal.IdSequence{Ids: hydratedIds, Generator: &r.ids}
+			}
+
+			renderCtx := internal.ContextWithIdGenerator(internal.ContextWithVNode(childCtx, vnode), ids)
 
-			walkChildren(childCtx, vnode, internal.JSX(internal.Fragment{}, vnode.Type.Render(internal.ContextWithVNode(childCtx, vnode), vnode.InputChildren...)))
+			children := vnode.Type.Render(renderCtx, vnode.InputChildren...)
+
+			if r.tracer != nil {
+				selfDuration += time.Since(start)
+			}
+
+			walkChildren(childCtx, vnode, internal.JSX(internal.Fragment{}, children))
 
 			r.mount(childCtx, oldVNode, vnode)
+		}
 
-			rendered := *vnode
+		renderComponent := func(oldVNode *VNode) {
+			if isBoundary(vnode) {
+				r.renderBoundary(vnode, oldVNode, render)
+			} else {
+				render(oldVNode)
+			}
+		}
 
-			vnode.OnUpdate(func(vn *VNode) {
-				doRender(ctx, &rendered, vn)
-			})
+		doRender := func(oldVNode *VNode, reason RenderReason) {
+			profiler, isProfiler := vnode.Type.(internal.Profiler)
 
-			r.cq.Dispatch(func() {
-				vnode.DidMount()
-			})
+			if r.tracer == nil && !isProfiler {
+				renderComponent(oldVNode)
+			} else {
+				start := time.Now()
+				selfDuration = 0
+
+				mark := r.cq.mark()
+
+				func() {
+					owner := r.cq.own(vnode)
+					defer r.cq.own(owner)
+
+					renderComponent(oldVNode)
+				}()
+
+				d := time.Since(start)
+
+				if r.tracer != nil {
+					r.tracer.TraceRender(RenderRecord{
+						Name:         typeName(vnode),
+						Key:          string(vnode.Key),
+						Reason:       reason,
+						Start:        start,
+						Duration:     d,
+						SelfDuration: selfDuration,
+						Hooks:        vnode.HookIndex(),
+						Operations:   r.cq.operationsOf(vnode, mark),
+					})
+				}
+
+				if isProfiler {
+					phase := "update"
+					if oldVNode == nil {
+						phase = "mount"
+					}
+					r.reportProfile(profiler, internal.ProfileInfo{ID: profiler.ID, Phase: phase, StartTime: start, ActualDuration: d})
+				}
+			}
+
+			rendered = *vnode
+
+			r.didMount(vnode)
 		}
 
-		doRender(ctx, oldVNode, vnode)
+		// bind before render, setState could be called from other goroutines once hooks created.
+		vnode.OnUpdate(func(vn *VNode, reason internal.UpdateReason) {
+			r.scheduleUpdate(vn, renderReasonOf(reason), func(reason RenderReason) {
+				start := time.Now()
+
+				r.renderUpdate(vn, &rendered, func(oldVNode *VNode) {
+					doRender(oldVNode, reason)
+				})
+
+				// Profilers above are not rendered, but should know the updates of descendants.
+				r.profile(vn.Parent, start, time.Since(start))
+			})
+		})
+
+		if oldVNode == nil {
+			doRender(oldVNode, RenderByMount)
+		} else {
+			doRender(oldVNode, RenderByParent)
+		}
 	}
+
+	r.stack.pop()
+
+	return vnode
+}
+
+// patchVNodeBefore patches vnode, and places nodes of it before beforeNode when vnode has no node itself.
+func (r *Root) patchVNodeBefore(ctx context.Context, oldVNode *VNode, vnode *VNode, beforeNode Element) *VNode {
+	before := r.before
+	r.before = beforeNode
+	defer func() {
+		r.before = before
+	}()
+
+	return r.patchVNode(ctx, oldVNode, vnode)
+}
+
+func (r *Root) didMount(vnode *VNode) {
+	r.cq.DispatchLayout(func() {
+		r.runEffects(vnode, vnode.DidMount)
+	})
+	r.cq.DispatchPassive(func() {
+		r.runEffects(vnode, vnode.DidPaint)
+	})
 }
 
 func (r *Root) mount(childCtx context.Context, oldVNode *VNode, vnode *VNode) {
 	if oldVNode == nil {
+		if h := r.hydration; h != nil {
+			if r.hydrate(childCtx, h, vnode) {
+				return
+			}
+			// mismatched, mount fresh nodes and put them at the hydrating position
+			r.hydration = nil
+			r.mount(childCtx, nil, vnode)
+			r.hydration = h
+			if !vnode.IsRoot && vnode.Node != nil {
+				h.insert(r, vnode.Node)
+			}
+			return
+		}
+
 		switch x := vnode.Type.(type) {
 		case internal.Text:
 			vnode.Node = r.createTextNode(string(x))
 			return
 		case internal.Element:
 			vnode.Node = r.createElement(string(x))
-			r.patchNodeAttrs(nil, vnode)
+			r.patchNodeAttrs(nil, vnode, false)
 		}
 
 		mounted := vnode.MountedNode()
 
-		// TODO mv this after mount
-		r.addVNodes(childCtx, mounted, nil, vnode.Children, 0, len(vnode.Children)-1)
+		r.addVNodes(childCtx, mounted, r.beforeNodeOf(vnode), vnode.Children, 0, len(vnode.Children)-1)
+
+		if _, ok := vnode.Type.(internal.Element); ok {
+			r.patchNodeAttrs(nil, vnode, true)
+		}
 
 		return
 	}
@@ -123,30 +395,46 @@ func (r *Root) mount(childCtx context.Context, oldVNode *VNode, vnode *VNode) {
 		}
 		return
 	case internal.Element:
-		r.patchNodeAttrs(oldVNode, vnode)
+		r.patchNodeAttrs(oldVNode, vnode, false)
 	}
 
 	oldChildren := oldVNode.Children
 	childVNodes := vnode.Children
 
 	mounted := vnode.MountedNode()
+	beforeNode := r.beforeNodeOf(vnode)
 
 	if len(oldChildren) != 0 && len(childVNodes) != 0 {
-		r.patchVNodes(childCtx, mounted, oldChildren, childVNodes)
+		r.patchVNodes(childCtx, mounted, beforeNode, oldChildren, childVNodes)
 	} else if len(childVNodes) != 0 {
-		r.addVNodes(childCtx, mounted, nil, childVNodes, 0, len(childVNodes)-1)
+		r.addVNodes(childCtx, mounted, beforeNode, childVNodes, 0, len(childVNodes)-1)
 	} else if len(oldChildren) != 0 {
 		r.removeVNodes(childCtx, mounted, oldChildren, 0, len(oldChildren)-1)
 	}
+
+	if _, ok := vnode.Type.(internal.Element); ok {
+		r.patchNodeAttrs(oldVNode, vnode, true)
+	}
+}
+
+// beforeNodeOf returns the node which children of vnode should be placed before
+func (r *Root) beforeNodeOf(vnode *VNode) Element {
+	if vnode.Node != nil {
+		return nil
+	}
+	return r.before
 }
 
 func walkChildren(ctx context.Context, v *internal.VNode, children ...interface{}) {
-	v.Children = children
+	// copied, patching will replace the reused VNodes, the slice may be shared by user
+	v.Children = append(make([]interface{}, 0, len(children)), children...)
+
+	var keys map[Key]bool
 
 	for i := range v.Children {
 		switch x := v.Children[i].(type) {
 		case internal.Key:
-		case *internal.Ref:
+		case internal.RefObject:
 			v.Ref = x
 		case internal.Attrs:
 			v.Attrs.Merge(x)
@@ -154,150 +442,375 @@ func walkChildren(ctx context.Context, v *internal.VNode, children ...interface{
 			v.Attrs.Merge(x.Attrs(ctx))
 		case *internal.VNode:
 			x.Parent = v
+			if x.Key != "" {
+				if keys == nil {
+					keys = map[Key]bool{}
+				}
+				if keys[x.Key] {
+					log.Printf("gox: duplicate key %q in children of %s, children with the same key may be dropped or mismatched when updating", x.Key, typeName(v))
+				}
+				keys[x.Key] = true
+			}
 		default:
 			if x != nil {
-				fmt.Printf("unsupported child %T in %T\n", x, v.Type)
+				log.Printf("gox: unsupported child %T in %s, ignored", x, typeName(v))
 			}
 		}
 	}
 }
 
-func indexVNodeList(l []interface{}, n, i int) *VNode {
-	if maxIndex := n - 1; maxIndex >= i && i >= 0 {
-		if vn, ok := l[i].(*VNode); ok {
-			return vn
+// vnodesOf collects VNodes of children, and the indexes of them
+func vnodesOf(children []interface{}) ([]*VNode, []int) {
+	vnodes := make([]*VNode, 0, len(children))
+	indexes := make([]int, 0, len(children))
+	for i := range children {
+		if vn, ok := children[i].(*VNode); ok {
+			vnodes = append(vnodes, vn)
+			indexes = append(indexes, i)
 		}
 	}
-	return nil
+	return vnodes, indexes
 }
 
-func (r *Root) patchVNodes(ctx context.Context, parentNode Element, oldChildren []interface{}, newChildren []interface{}) {
-	oldStartIdx := 0
-	newStartIdx := 0
-	oldN := len(oldChildren)
-	oldEndIdx := oldN - 1
-	oldStartVNode := indexVNodeList(oldChildren, oldN, 0)
-	oldEndVNode := indexVNodeList(oldChildren, oldN, oldEndIdx)
-	newN := len(newChildren)
-	newEndIdx := newN - 1
-	newStartVNode := indexVNodeList(newChildren, newN, 0)
-	newEndVNode := indexVNodeList(newChildren, newN, newEndIdx)
-
-	var oldKeyToIdx map[Key]int
-	var idxInOld int
-	var elmToMove *VNode
-	var beforeNode Element
-
-	for oldStartIdx <= oldEndIdx && newStartIdx <= newEndIdx {
-		if oldStartVNode == nil {
-			oldStartIdx++
-			oldStartVNode = indexVNodeList(oldChildren, oldN, oldStartIdx) // VNode might have been moved left
-		} else if oldEndVNode == nil {
-			oldEndIdx--
-			oldEndVNode = indexVNodeList(oldChildren, oldN, oldEndIdx)
-		} else if newStartVNode == nil {
-			newStartIdx++
-			newStartVNode = indexVNodeList(newChildren, newN, newStartIdx)
-		} else if newEndVNode == nil {
-			newEndIdx--
-			newEndVNode = indexVNodeList(newChildren, newN, newEndIdx)
-		} else if r.sameVNode(oldStartVNode, newStartVNode) {
-			r.patchVNode(ctx, oldStartVNode, newStartVNode)
-			oldStartIdx++
-			oldStartVNode = indexVNodeList(oldChildren, oldN, oldStartIdx)
-			newStartIdx++
-			newStartVNode = indexVNodeList(newChildren, newN, newStartIdx)
-		} else if r.sameVNode(oldEndVNode, newEndVNode) {
-			r.patchVNode(ctx, oldEndVNode, newEndVNode)
-			oldEndIdx--
-			oldEndVNode = indexVNodeList(oldChildren, oldN, oldEndIdx)
-			newEndIdx--
-			newEndVNode = indexVNodeList(newChildren, newN, newEndIdx)
-		} else if r.sameVNode(oldStartVNode, newEndVNode) {
-			// VNode moved right
-			r.patchVNode(ctx, oldStartVNode, newEndVNode)
-			r.insertBefore(parentNode, oldStartVNode.Node, oldEndVNode.Node.NextSibling())
-			oldStartIdx++
-			oldStartVNode = indexVNodeList(oldChildren, oldN, oldStartIdx)
-			newEndIdx--
-			newEndVNode = indexVNodeList(newChildren, newN, newEndIdx)
-		} else if r.sameVNode(oldEndVNode, newStartVNode) {
-			// VNode moved left
-			r.patchVNode(ctx, oldEndVNode, newStartVNode)
-			r.insertBefore(parentNode, oldEndVNode.Node, oldStartVNode.Node)
-
-			oldEndIdx--
-			oldEndVNode = indexVNodeList(oldChildren, oldN, oldEndIdx)
-			newStartIdx++
-			newStartVNode = indexVNodeList(newChildren, newN, newStartIdx)
+// patchVNodes patches the common head and tail first,
+// then matches the rest by key, and only moves VNodes out of the longest increasing subsequence of old positions.
+// nodes of the children are placed before beforeNode.
+func (r *Root) patchVNodes(ctx context.Context, parentNode Element, beforeNode Element, oldChildren []interface{}, newChildren []interface{}) {
+	oldVNodes, _ := vnodesOf(oldChildren)
+	newVNodes, newIndexes := vnodesOf(newChildren)
+
+	patch := func(oldVNode *VNode, i int, beforeNode Element) {
+		vn := r.patchVNodeBefore(ctx, oldVNode, newVNodes[i], beforeNode)
+		newVNodes[i] = vn
+		newChildren[newIndexes[i]] = vn
+	}
+
+	start := 0
+	oldEnd := len(oldVNodes) - 1
+	newEnd := len(newVNodes) - 1
+
+	// nodes of the rest old VNodes are still in place
+	beforeOld := func(i int) Element {
+		for ; i <= oldEnd; i++ {
+			if n := firstHostNode(oldVNodes[i]); n != nil {
+				return n
+			}
+		}
+		for i := newEnd + 1; i < len(newVNodes); i++ {
+			if n := firstHostNode(newVNodes[i]); n != nil {
+				return n
+			}
+		}
+		return beforeNode
+	}
+
+	for start <= oldEnd && start <= newEnd && r.sameVNode(oldVNodes[start], newVNodes[start]) {
+		patch(oldVNodes[start], start, beforeOld(start+1))
+		start++
+	}
+
+	// the first node of patched tail
+	tail := beforeNode
+
+	for start <= oldEnd && start <= newEnd && r.sameVNode(oldVNodes[oldEnd], newVNodes[newEnd]) {
+		patch(oldVNodes[oldEnd], newEnd, tail)
+		if n := firstHostNode(newVNodes[newEnd]); n != nil {
+			tail = n
+		}
+		oldEnd--
+		newEnd--
+	}
+
+	if start > oldEnd {
+		for i := start; i <= newEnd; i++ {
+			r.mountVNode(ctx, parentNode, newVNodes[i], tail)
+		}
+		return
+	}
+
+	if start > newEnd {
+		for i := start; i <= oldEnd; i++ {
+			r.removeVNode(parentNode, oldVNodes[i])
+		}
+		return
+	}
+
+	keyToNewIdx := map[Key]int{}
+	for i := start; i <= newEnd; i++ {
+		if key := newVNodes[i].Key; key != "" {
+			// duplicated keys are warned when walking children, the first one wins.
+			if _, ok := keyToNewIdx[key]; !ok {
+				keyToNewIdx[key] = i
+			}
+		}
+	}
+
+	// old index + 1 of each new VNode in the middle, 0 means new VNode should be mounted.
+	newIdxToOldIdx := make([]int, newEnd-start+1)
+	moved := false
+	maxNewIdx := 0
+
+	for i := start; i <= oldEnd; i++ {
+		oldVNode := oldVNodes[i]
+		newIdx := -1
+
+		if oldVNode.Key != "" {
+			if idx, ok := keyToNewIdx[oldVNode.Key]; ok {
+				newIdx = idx
+			}
 		} else {
-			if oldKeyToIdx == nil {
-				oldKeyToIdx = createKeyToOldIdx(oldChildren, oldStartIdx, oldEndIdx)
+			for j := start; j <= newEnd; j++ {
+				if newIdxToOldIdx[j-start] == 0 && newVNodes[j].Key == "" && r.sameVNode(oldVNode, newVNodes[j]) {
+					newIdx = j
+					break
+				}
 			}
+		}
 
-			idxInOld = oldKeyToIdx[newStartVNode.Key]
+		if newIdx == -1 || newIdxToOldIdx[newIdx-start] != 0 || !r.sameVNode(oldVNode, newVNodes[newIdx]) {
+			r.removeVNode(parentNode, oldVNode)
+			continue
+		}
 
-			if idxInOld == 0 {
-				r.patchVNode(ctx, nil, newStartVNode)
-				r.insertBefore(parentNode, newStartVNode.Node, oldStartVNode.Node)
+		newIdxToOldIdx[newIdx-start] = i + 1
+
+		if newIdx >= maxNewIdx {
+			maxNewIdx = newIdx
+		} else {
+			moved = true
+		}
+
+		// patched in place, the moved ones will be placed with all nodes of them.
+		patch(oldVNode, newIdx, beforeOld(i+1))
+	}
+
+	var stable []int
+	if moved {
+		stable = longestIncreasingSubsequence(newIdxToOldIdx)
+	}
+	s := len(stable) - 1
+
+	// from tail to head, so the next sibling is always placed.
+	for j := len(newIdxToOldIdx) - 1; j >= 0; j-- {
+		i := start + j
+
+		if newIdxToOldIdx[j] == 0 {
+			r.mountVNode(ctx, parentNode, newVNodes[i], tail)
+		} else if moved {
+			if s >= 0 && stable[s] == j {
+				s--
 			} else {
-				elmToMove = indexVNodeList(oldChildren, oldN, idxInOld)
-				if !internal.SameComponent(elmToMove.Type, newStartVNode.Type) {
-					r.patchVNode(ctx, nil, newStartVNode)
-					r.insertBefore(parentNode, newStartVNode.Node, oldStartVNode.Node)
-				} else {
-					r.patchVNode(ctx, elmToMove, newStartVNode)
-					oldChildren[idxInOld] = nil
-					r.insertBefore(parentNode, elmToMove.Node, oldStartVNode.Node)
-				}
+				r.moveVNode(parentNode, newVNodes[i], tail)
 			}
-			newStartIdx++
-			newStartVNode = indexVNodeList(newChildren, newN, newStartIdx)
+		}
+
+		if n := firstHostNode(newVNodes[i]); n != nil {
+			tail = n
 		}
 	}
+}
 
-	if oldStartIdx <= oldEndIdx || newStartIdx <= newEndIdx {
-		if oldStartIdx > oldEndIdx {
-			beforeNode = nil
-			if n := indexVNodeList(newChildren, newN, newEndIdx+1); n != nil {
-				beforeNode = n.Node
+// longestIncreasingSubsequence returns indexes of the longest increasing subsequence of values,
+// zero values are skipped.
+func longestIncreasingSubsequence(values []int) []int {
+	// tails[k] is the index of the smallest tail value of all increasing subsequences with length k+1
+	tails := make([]int, 0, len(values))
+	prev := make([]int, len(values))
+
+	for i, v := range values {
+		if v == 0 {
+			continue
+		}
+
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if values[tails[mid]] < v {
+				lo = mid + 1
+			} else {
+				hi = mid
 			}
-			r.addVNodes(ctx, parentNode, beforeNode, newChildren, newStartIdx, newEndIdx)
+		}
+
+		if lo > 0 {
+			prev[i] = tails[lo-1]
+		}
+
+		if lo == len(tails) {
+			tails = append(tails, i)
 		} else {
-			r.removeVNodes(ctx, parentNode, oldChildren, oldStartIdx, oldEndIdx)
+			tails[lo] = i
 		}
 	}
+
+	seq := make([]int, len(tails))
+	if len(tails) > 0 {
+		i := tails[len(tails)-1]
+		for k := len(tails) - 1; k >= 0; k-- {
+			seq[k] = i
+			i = prev[i]
+		}
+	}
+	return seq
 }
 
 func (r *Root) addVNodes(ctx context.Context, parentNode Element, beforeNode Element, vnodes []interface{}, startIdx int, endIdx int) {
 	for startIdx <= endIdx {
 		if vn, ok := vnodes[startIdx].(*VNode); ok {
-			r.patchVNode(ctx, nil, vn)
-			if !vn.IsRoot {
-				r.insertBefore(parentNode, vn.Node, beforeNode)
-			}
+			r.mountVNode(ctx, parentNode, vn, beforeNode)
 		}
 		startIdx++
 	}
 }
 
+// mountVNode mounts vnode, and inserts the node of it before beforeNode.
+func (r *Root) mountVNode(ctx context.Context, parentNode Element, vnode *VNode, beforeNode Element) {
+	// Fragment or component places children when mounting
+	r.patchVNodeBefore(ctx, nil, vnode, beforeNode)
+
+	// hydrated node is already in the document
+	if vnode.Node != nil && !vnode.IsRoot && r.hydration == nil {
+		r.insertBefore(parentNode, vnode.Node, beforeNode)
+	}
+}
+
+// moveVNode moves all nodes of vnode before beforeNode
+func (r *Root) moveVNode(parentNode Element, vnode *VNode, beforeNode Element) {
+	if vnode.IsRoot {
+		// nodes of Portal are not under parentNode
+		return
+	}
+
+	if vnode.Node != nil {
+		r.insertBefore(parentNode, vnode.Node, beforeNode)
+		return
+	}
+
+	for i := range vnode.Children {
+		if child, ok := vnode.Children[i].(*VNode); ok {
+			r.moveVNode(parentNode, child, beforeNode)
+		}
+	}
+}
+
+// firstHostNode returns the first node placed under the parent node of vnode,
+// nil when Fragment or component rendered nothing.
+func firstHostNode(vnode *VNode) Element {
+	if vnode.IsRoot {
+		return nil
+	}
+
+	if vnode.Node != nil {
+		return vnode.Node
+	}
+
+	for i := range vnode.Children {
+		if child, ok := vnode.Children[i].(*VNode); ok {
+			if n := firstHostNode(child); n != nil {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// nextHostNode returns the first node after all nodes of vnode in the rendered tree,
+// nil when vnode is the last one of the mounted node.
+func nextHostNode(vnode *VNode) Element {
+	for v := vnode; v.Parent != nil; v = v.Parent {
+		children := v.Parent.Children
+
+		after := false
+		for i := range children {
+			child, ok := children[i].(*VNode)
+			if !ok {
+				continue
+			}
+			if after {
+				if n := firstHostNode(child); n != nil {
+					return n
+				}
+			} else if child == v {
+				after = true
+			}
+		}
+
+		if v.Parent.Node != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
 func (r *Root) removeVNodes(ctx context.Context, parentNode Element, vnodes []interface{}, startIdx int, endIdx int) {
 	for startIdx <= endIdx {
 		if vn, ok := vnodes[startIdx].(*VNode); ok {
-			if vn.Node == nil {
-				r.removeVNodes(ctx, parentNode, vn.Children, 0, len(vn.Children)-1)
-			} else {
-				if !vn.IsRoot {
-					r.removeChild(parentNode, vn.Node)
-				}
-			}
-			_ = vn.Destroy()
+			r.removeVNode(parentNode, vn)
 		}
 		startIdx++
 	}
 }
 
-func (r *Root) patchNodeAttrs(oldVNode *VNode, vnode *VNode) {
+// removeVNode removes all nodes of vnode from parentNode, and destroys vnode with all descendants.
+func (r *Root) removeVNode(parentNode Element, vnode *VNode) {
+	switch {
+	case vnode.IsRoot:
+		// nodes of Portal are under its own node
+		for i := range vnode.Children {
+			if child, ok := vnode.Children[i].(*VNode); ok {
+				r.removeVNode(vnode.Node, child)
+			}
+		}
+	case vnode.Node != nil:
+		r.removeChild(parentNode, vnode.Node)
+		// descendants are removed with the node
+		for i := range vnode.Children {
+			if child, ok := vnode.Children[i].(*VNode); ok {
+				r.destroyVNode(child)
+			}
+		}
+	default:
+		for i := range vnode.Children {
+			if child, ok := vnode.Children[i].(*VNode); ok {
+				r.removeVNode(parentNode, child)
+			}
+		}
+	}
+
+	r.destroy(vnode)
+}
+
+// destroyVNode destroys vnode with all descendants, children first.
+func (r *Root) destroyVNode(vnode *VNode) {
+	if vnode.IsRoot {
+		// nodes of Portal are not removed with the parent node
+		r.removeVNode(nil, vnode)
+		return
+	}
+
+	for i := range vnode.Children {
+		if child, ok := vnode.Children[i].(*VNode); ok {
+			r.destroyVNode(child)
+		}
+	}
+
+	r.destroy(vnode)
+}
+
+// destroy releases event handlers of vnode and destroys it once committed,
+// so VNodes removed in a rolled back render are kept as they were.
+func (r *Root) destroy(vnode *VNode) {
+	r.cq.Dispatch("destroy", func() {
+		if vnode.Node != nil {
+			r.releaseEventHandlers(vnode.Node)
+		}
+		_ = vnode.Destroy()
+	})
+}
+
+// patchNodeAttrs patches attrs of vnode set before children mounted,
+// or the ones set after children mounted when afterChildren, like value of select.
+func (r *Root) patchNodeAttrs(oldVNode *VNode, vnode *VNode, afterChildren bool) {
 	oldAttrs := Attrs{}
 	attrs := Attrs{}
 
@@ -315,62 +828,87 @@ func (r *Root) patchNodeAttrs(oldVNode *VNode, vnode *VNode) {
 
 	// update modified attributes, add new attributes
 	for key := range attrs {
+		if isSetAfterChildren(vnode, key) != afterChildren {
+			continue
+		}
 		cur := attrs[key]
+		if eventType, capture, ok := parseEventAttr(key); ok {
+			if handler, ok := toEventHandler(cur); ok {
+				// always bind the latest handler
+				r.setEventHandler(mountPointOf(vnode), vnode.Node, eventType, capture, handler)
+				continue
+			}
+			if cur == nil {
+				r.removeEventHandler(vnode.Node, eventType, capture)
+				continue
+			}
+		}
+		if key == "style" {
+			old, ok := oldAttrs[key]
+			r.patchStyle(vnode, old, cur, ok)
+			continue
+		}
 		if old, ok := oldAttrs[key]; ok {
-			if cur != old {
-				r.setAttribute(vnode.Node, key, cur)
+			if cur != old || isLiveProperty(vnode, key) {
+				r.setAttr(vnode, key, cur, true)
 			}
 		} else {
-			r.setAttribute(vnode.Node, key, cur)
+			r.setAttr(vnode, key, cur, false)
 		}
 	}
 
 	for key := range oldAttrs {
 		if _, ok := attrs[key]; !ok {
-			r.removeAttribute(vnode.Node, key)
+			if isSetAfterChildren(vnode, key) != afterChildren {
+				continue
+			}
+			if eventType, capture, ok := parseEventAttr(key); ok {
+				if _, ok := toEventHandler(oldAttrs[key]); ok {
+					r.removeEventHandler(vnode.Node, eventType, capture)
+					continue
+				}
+			}
+			r.removeAttr(vnode, key)
 		}
 	}
 }
 
-func createKeyToOldIdx(childVNodes []interface{}, beginIdx int, endIdx int) map[Key]int {
-	keyToIndexMap := map[Key]int{}
-	for i := beginIdx; i <= endIdx; i++ {
-		if vn, ok := childVNodes[i].(*VNode); ok {
-			key := vn.Key
-			if key != "" {
-				keyToIndexMap[key] = i
-			}
+// mountPointOf returns the node of the root or portal which vnode rendered into
+func mountPointOf(vnode *VNode) Element {
+	for vn := vnode; vn != nil; vn = vn.Parent {
+		if vn.IsRoot && vn.Node != nil {
+			return vn.Node
 		}
 	}
-	return keyToIndexMap
+	return nil
 }
 
 func (r *Root) insertBefore(parent, new, old Node) {
-	r.cq.Dispatch(func() {
+	r.cq.Dispatch("insertBefore", func() {
 		parent.InsertBefore(new, old)
 	})
 }
 
 func (r *Root) removeChild(parent, old Node) {
-	r.cq.Dispatch(func() {
+	r.cq.Dispatch("removeChild", func() {
 		parent.RemoveChild(old)
 	})
 }
 
 func (r *Root) setAttribute(node Element, k string, v interface{}) {
-	r.cq.Dispatch(func() {
+	r.cq.Dispatch("setAttribute", func() {
 		node.SetAttribute(k, v)
 	})
 }
 
 func (r *Root) removeAttribute(node Element, key string) {
-	r.cq.Dispatch(func() {
+	r.cq.Dispatch("removeAttribute", func() {
 		node.RemoveAttribute(key)
 	})
 }
 
 func (r *Root) setTextContent(n Node, text string) {
-	r.cq.Dispatch(func() {
+	r.cq.Dispatch("setTextContent", func() {
 		n.SetTextContent(text)
 	})
 }
@@ -385,7 +923,7 @@ func (e *elementDeffer) Underlying() interface{} {
 
 func (r *Root) createElement(tag string) Element {
 	e := &elementDeffer{}
-	r.cq.Dispatch(func() {
+	r.cq.Dispatch("createElement", func() {
 		e.Element = r.doc.CreateElement(tag)
 	})
 	return e
@@ -393,7 +931,7 @@ func (r *Root) createElement(tag string) Element {
 
 func (r *Root) createTextNode(text string) Element {
 	e := &elementDeffer{}
-	r.cq.Dispatch(func() {
+	r.cq.Dispatch("createTextNode", func() {
 		e.Element = r.doc.CreateTextNode(text)
 	})
 	return e
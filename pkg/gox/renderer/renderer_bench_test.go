@@ -3,6 +3,7 @@ package renderer
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/go-courier/gox/pkg/gox/internal"
@@ -60,5 +61,95 @@ func Benchmark(b *testing.B) {
 				_ = r.Render(ctx, Div(children...))
 			}
 		})
+
+		for _, memo := range []bool{false, true} {
+			b.Run(fmt.Sprintf("10000 rows, memo %v", memo), func(b *testing.B) {
+				ctx := context.Background()
+				root := Document.CreateElement("body")
+				r := CreateRoot(root)
+
+				render := func(changed int) {
+					rows := make([]interface{}, 10000)
+					for i := range rows {
+						label := fmt.Sprintf("%d", i)
+						if i == changed {
+							label = "changed"
+						}
+
+						var c Component = benchRow{Label: label}
+						if memo {
+							c = Memo(c)
+						}
+						rows[i] = H(c)(Key(fmt.Sprintf("%d", i)))
+					}
+					_ = r.Render(ctx, Div(rows...))
+				}
+
+				render(-1)
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					render(i % 10000)
+				}
+			})
+		}
 	})
+
+	b.Run("Keyed Nodes", func(b *testing.B) {
+		keyed := func(keys []int) *VNode {
+			children := make([]interface{}, len(keys))
+			for i, k := range keys {
+				children[i] = Li(Key(fmt.Sprintf("%d", k)), fmt.Sprintf("%d", k))
+			}
+			return Ul(children...)
+		}
+
+		seq := make([]int, 1000)
+		for i := range seq {
+			seq[i] = i
+		}
+
+		reversed := make([]int, len(seq))
+		for i := range seq {
+			reversed[i] = seq[len(seq)-1-i]
+		}
+
+		shuffled := append([]int{}, seq...)
+		rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+
+		inserted := append(append(append([]int{}, seq[:500]...), len(seq)), seq[500:]...)
+
+		for _, c := range []struct {
+			name string
+			keys []int
+		}{
+			{"shuffle", shuffled},
+			{"reverse", reversed},
+			{"insert middle", inserted},
+		} {
+			b.Run(c.name, func(b *testing.B) {
+				ctx := context.Background()
+				root := Document.CreateElement("body")
+				r := CreateRoot(root)
+
+				for i := 0; i < b.N; i++ {
+					if i%2 == 0 {
+						_ = r.Render(ctx, keyed(c.keys))
+					} else {
+						_ = r.Render(ctx, keyed(seq))
+					}
+				}
+			})
+		}
+	})
+}
+
+type benchRow struct {
+	Label string
+}
+
+func (row benchRow) Render(ctx context.Context, children ...interface{}) interface{} {
+	return Span(row.Label)
 }
@@ -0,0 +1,115 @@
+package renderer_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+func TestRenderConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func() (*renderer.Root, Element, *renderCounter, *SetStateFunc[int], *SetStateFunc[int]) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+
+		counter := &renderCounter{}
+		setCount, setChild := new(SetStateFunc[int]), new(SetStateFunc[int])
+
+		_ = r.Render(ctx, H(SchedulerParent{Counter: counter, SetCount: setCount, SetChild: setChild})())
+
+		return r, root, counter, setCount, setChild
+	}
+
+	html := func(r *renderer.Root, root Element) (s string) {
+		r.Act(func() {
+			s = htmlOf(root)
+		})
+		return
+	}
+
+	t.Run("should apply all updates from concurrent setters", func(t *testing.T) {
+		r, root, _, setCount, setChild := setup()
+		defer r.Close()
+
+		increase := func(prev int) int {
+			return prev + 1
+		}
+
+		wg := &sync.WaitGroup{}
+
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					(*setCount)(increase)
+					(*setChild)(increase)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		r.FlushSync(nil)
+
+		gomega.NewWithT(t).Expect(html(r, root)).To(gomega.Equal(`<body><div>1000<span>1000</span></div></body>`))
+	})
+
+	t.Run("should drop updates of unmounted components", func(t *testing.T) {
+		r, root, counter, setCount, setChild := setup()
+		defer r.Close()
+
+		wg := &sync.WaitGroup{}
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					(*setCount).Set(i*100 + j)
+					(*setChild).Set(i*100 + j)
+				}
+			}(i)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		_ = r.Render(ctx, Fragment())
+
+		wg.Wait()
+
+		rendered := atomic.LoadInt32(&counter.parent)
+
+		r.FlushSync(nil)
+
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&counter.parent)).To(gomega.Equal(rendered))
+		gomega.NewWithT(t).Expect(html(r, root)).To(gomega.Equal(`<body></body>`))
+	})
+
+	t.Run("should drop pending updates when closed", func(t *testing.T) {
+		r, _, counter, setCount, _ := setup()
+
+		r.RunWithPriority(renderer.LowPriority, func() {
+			(*setCount).Set(1)
+		})
+
+		gomega.NewWithT(t).Expect(r.Close()).To(gomega.Succeed())
+
+		(*setCount).Set(2)
+
+		gomega.NewWithT(t).Consistently(func() int32 {
+			return atomic.LoadInt32(&counter.parent)
+		}, 300*time.Millisecond).Should(gomega.Equal(int32(1)))
+
+		gomega.NewWithT(t).Expect(r.Render(ctx, Fragment())).To(gomega.Equal(renderer.ErrRootClosed))
+		gomega.NewWithT(t).Expect(r.Close()).To(gomega.Succeed())
+	})
+}
@@ -13,6 +13,8 @@ import (
 )
 
 func TestRender(t *testing.T) {
+	ctx := context.Background()
+
 	t.Run("should update vnode", func(t *testing.T) {
 		buf := bytes.NewBuffer(nil)
 		ctx := context.Background()
@@ -56,6 +58,16 @@ func TestRender(t *testing.T) {
 		}
 	})
 
+	t.Run("should ignore unsupported children", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		err := r.Render(ctx, Div(struct{}{}, Span("1"), make(chan int)))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>1</span></div></body>`))
+	})
+
 	t.Run("should render to portal", func(t *testing.T) {
 		buf := bytes.NewBuffer(nil)
 		ctx := context.Background()
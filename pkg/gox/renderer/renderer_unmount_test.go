@@ -0,0 +1,91 @@
+package renderer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+// listenersCounter counts listeners on the mount point
+type listenersCounter struct {
+	Element
+	listeners int
+}
+
+func (c *listenersCounter) AddEventListener(eventType string, listener func(e Event), args ...interface{}) {
+	c.listeners++
+	c.Element.AddEventListener(eventType, listener, args...)
+}
+
+func (c *listenersCounter) RemoveEventListener(eventType string, listener func(e Event), args ...interface{}) {
+	c.listeners--
+	c.Element.RemoveEventListener(eventType, listener, args...)
+}
+
+type RefHolder struct {
+	Destroyed *[]string
+	Ref       **Ref[Element]
+}
+
+func (h RefHolder) Render(ctx context.Context, children ...interface{}) interface{} {
+	ref := UseRef[Element](ctx, nil)
+	*h.Ref = ref
+
+	return Div(ref, H(Cleanup{Name: "child", Destroyed: h.Destroyed})(
+		H(Cleanup{Name: "grandchild", Destroyed: h.Destroyed})(),
+	))
+}
+
+func TestUnmount(t *testing.T) {
+	ctx := context.Background()
+
+	root := &listenersCounter{Element: Document.CreateElement("body")}
+	portal := Document.CreateElement("div")
+	r := renderer.CreateRoot(root)
+
+	destroyed := make([]string, 0)
+	ref := new(*Ref[Element])
+
+	_ = r.Render(ctx, H(Cleanup{Name: "parent", Destroyed: &destroyed})(
+		H(RefHolder{Destroyed: &destroyed, Ref: ref})(),
+		Button(Attr("onClick", func() {})),
+		renderer.Portal(portal)(
+			H(Cleanup{Name: "portal", Destroyed: &destroyed})(Span("in portal")),
+		),
+	))
+	r.FlushSync(nil)
+
+	gomega.NewWithT(t).Expect(htmlOf(root.Element)).To(gomega.Equal(`<body><div></div><button></button></body>`))
+	gomega.NewWithT(t).Expect(htmlOf(portal)).To(gomega.Equal(`<div><span>in portal</span></div>`))
+	gomega.NewWithT(t).Expect((*ref).Current).NotTo(gomega.BeNil())
+	gomega.NewWithT(t).Expect(root.listeners).To(gomega.Equal(1))
+
+	err := r.Unmount()
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	t.Run("should remove all nodes", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(htmlOf(root.Element)).To(gomega.Equal(`<body></body>`))
+		gomega.NewWithT(t).Expect(htmlOf(portal)).To(gomega.Equal(`<div></div>`))
+	})
+
+	t.Run("should clean up effects children first", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(destroyed).To(gomega.Equal([]string{"grandchild", "child", "portal", "parent"}))
+	})
+
+	t.Run("should release refs and listeners", func(t *testing.T) {
+		gomega.NewWithT(t).Expect((*ref).Current).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(root.listeners).To(gomega.Equal(0))
+	})
+
+	t.Run("should fail to render after unmounted", func(t *testing.T) {
+		err := r.Render(ctx, Div())
+		gomega.NewWithT(t).Expect(errors.Is(err, renderer.ErrRootUnmounted)).To(gomega.BeTrue())
+
+		gomega.NewWithT(t).Expect(r.Unmount()).To(gomega.BeNil())
+	})
+}
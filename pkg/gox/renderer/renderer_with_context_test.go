@@ -3,6 +3,7 @@ package renderer_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"testing"
 
 	. "github.com/go-courier/gox/pkg/css"
@@ -46,3 +47,104 @@ func TestRenderWithContext(t *testing.T) {
 		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(`<body><div class="app-tokvmb">1</div></body>`))
 	}
 }
+
+var themeContext = CreateContext("light")
+
+type ThemedLabel struct {
+	Rendered *int
+}
+
+func (l ThemedLabel) Render(ctx context.Context, children ...interface{}) interface{} {
+	*l.Rendered++
+	return Span(UseContext(ctx, themeContext))
+}
+
+type ThemeApp struct {
+	Rendered  *int
+	Label     *int
+	SetTheme  *SetStateFunc[string]
+	SetOthers *SetStateFunc[int]
+}
+
+func (a ThemeApp) Render(ctx context.Context, children ...interface{}) interface{} {
+	*a.Rendered++
+
+	theme, setTheme := UseState(ctx, "dark")
+	*a.SetTheme = setTheme
+
+	others, setOthers := UseState(ctx, 0)
+	*a.SetOthers = setOthers
+
+	return themeContext.Provide(theme)(
+		Div(
+			fmt.Sprint(others),
+			H(Memo(ThemedLabel{Rendered: a.Label}))(),
+		),
+	)
+}
+
+func TestRenderWithTypedContext(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should use default value without provider", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		rendered := 0
+		_ = r.Render(ctx, H(ThemedLabel{Rendered: &rendered})())
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><span>light</span></body>`))
+	})
+
+	t.Run("should re-render memoized consumers only when provided value changed", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		app, label := 0, 0
+		setTheme, setOthers := new(SetStateFunc[string]), new(SetStateFunc[int])
+
+		_ = r.Render(ctx, H(ThemeApp{Rendered: &app, Label: &label, SetTheme: setTheme, SetOthers: setOthers})())
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>0<span>dark</span></div></body>`))
+
+		r.Act(func() {
+			(*setOthers).Set(1)
+		})
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>1<span>dark</span></div></body>`))
+		gomega.NewWithT(t).Expect([]int{app, label}).To(gomega.Equal([]int{2, 1}))
+
+		r.Act(func() {
+			(*setTheme).Set("blue")
+		})
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>1<span>blue</span></div></body>`))
+		gomega.NewWithT(t).Expect([]int{app, label}).To(gomega.Equal([]int{3, 2}))
+	})
+
+	t.Run("should commit memoized consumers with the provider in one render", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		rendered := 0
+		label := H(Memo(ThemedLabel{Rendered: &rendered}))()
+
+		_ = r.Render(ctx, themeContext.Provide("light")(Div(label)))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>light</span></div></body>`))
+
+		_ = r.Render(ctx, themeContext.Provide("dark")(Div(label)))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>dark</span></div></body>`))
+		gomega.NewWithT(t).Expect(rendered).To(gomega.Equal(2))
+	})
+
+	t.Run("should render provided value to string", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		rendered := 0
+
+		_ = renderer.RenderToString(ctx, buf, themeContext.Provide("dark")(
+			H(ThemedLabel{Rendered: &rendered})(),
+		))
+
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(`<!--[--><!--[--><span>dark</span><!--]--><!--]-->`))
+	})
+}
@@ -0,0 +1,146 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type BrokenWidget struct {
+	Broken    *bool
+	SetBroken *SetStateFunc[bool]
+	InEffect  bool
+}
+
+func (w BrokenWidget) Render(ctx context.Context, children ...interface{}) interface{} {
+	broken, setBroken := UseState(ctx, *w.Broken)
+	if w.SetBroken != nil {
+		*w.SetBroken = setBroken
+	}
+
+	UseEffect(ctx, func() func() {
+		if w.InEffect && broken {
+			panic(errors.New("broken in effect"))
+		}
+		return nil
+	}, []interface{}{broken})
+
+	if !w.InEffect && broken {
+		var m map[string]string
+		m["nil"] = "map"
+	}
+
+	return Span("ok")
+}
+
+func TestRenderWithErrorBoundary(t *testing.T) {
+	ctx := context.Background()
+
+	var caught ErrorInfo
+
+	fallback := func(info ErrorInfo) interface{} {
+		caught = info
+		return Div("fallback: ", info.Err.Error())
+	}
+
+	t.Run("should render fallback when descendant panicked in render", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		broken := true
+
+		err := r.Render(ctx, Div(
+			ErrorBoundary(fallback)(
+				P(H(BrokenWidget{Broken: &broken})()),
+			),
+			Span("sibling"),
+		))
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><div>fallback: assignment to entry in nil map</div><span>sibling</span></div></body>`))
+		gomega.NewWithT(t).Expect(caught.ComponentStack).To(gomega.Equal("\n    in BrokenWidget\n    in p\n    in ErrorBoundary"))
+	})
+
+	t.Run("should render fallback when panicked in update, and render children again after reset", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		broken := false
+		setBroken := new(SetStateFunc[bool])
+
+		_ = r.Render(ctx, Div(
+			ErrorBoundary(fallback)(
+				H(BrokenWidget{Broken: &broken, SetBroken: setBroken})(),
+			),
+		))
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>ok</span></div></body>`))
+
+		r.Act(func() {
+			(*setBroken).Set(true)
+		})
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><div>fallback: assignment to entry in nil map</div></div></body>`))
+
+		r.Act(func() {
+			caught.Reset()
+		})
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>ok</span></div></body>`))
+	})
+
+	t.Run("should render fallback when panicked in effect", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		broken := true
+
+		_ = r.Render(ctx, ErrorBoundary(fallback)(
+			H(BrokenWidget{Broken: &broken, InEffect: true})(),
+		))
+
+		r.FlushSync(nil)
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>fallback: broken in effect</div></body>`))
+		gomega.NewWithT(t).Expect(caught.ComponentStack).To(gomega.Equal("\n    in BrokenWidget\n    in ErrorBoundary"))
+	})
+
+	t.Run("should return RenderPanic without ErrorBoundary", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		broken := true
+
+		err := r.Render(ctx, Div(H(BrokenWidget{Broken: &broken})()))
+
+		p := &renderer.RenderPanic{}
+		gomega.NewWithT(t).Expect(errors.As(err, &p)).To(gomega.BeTrue())
+		gomega.NewWithT(t).Expect(p.Origin.Type).To(gomega.Equal(BrokenWidget{Broken: &broken}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body></body>`))
+	})
+
+	t.Run("should render fallback to string", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+
+		broken := true
+
+		err := renderer.RenderToString(ctx, buf, Div(
+			ErrorBoundary(fallback)(
+				H(BrokenWidget{Broken: &broken})(),
+			),
+		))
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(`<div><!--[--><div>fallback: <!--|-->assignment to entry in nil map</div><!--]--></div>`))
+	})
+}
@@ -0,0 +1,224 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type Pair struct {
+	Label string
+}
+
+func (p Pair) Render(ctx context.Context, children ...interface{}) interface{} {
+	return Fragment(Dt(p.Label), Dd(p.Label))
+}
+
+type GrowingList struct {
+	SetCount *SetStateFunc[int]
+	Renders  *int32
+}
+
+func (l GrowingList) Render(ctx context.Context, children ...interface{}) interface{} {
+	if l.Renders != nil {
+		atomic.AddInt32(l.Renders, 1)
+	}
+
+	count, setCount := UseState(ctx, 1)
+	*l.SetCount = setCount
+
+	items := make([]interface{}, count)
+	for i := range items {
+		items[i] = Li(fmt.Sprint(i))
+	}
+	return Fragment(items...)
+}
+
+type Cleanup struct {
+	Name      string
+	Destroyed *[]string
+}
+
+func (c Cleanup) Render(ctx context.Context, children ...interface{}) interface{} {
+	UseEffect(ctx, func() func() {
+		return func() {
+			*c.Destroyed = append(*c.Destroyed, c.Name)
+		}
+	}, nil)
+
+	return Fragment(children...)
+}
+
+func TestRenderWithFragment(t *testing.T) {
+	ctx := context.Background()
+
+	pairs := func(labels ...string) *VNode {
+		children := make([]interface{}, len(labels))
+		for i, l := range labels {
+			children[i] = H(Pair{Label: l})(Key(l))
+		}
+		return Dl(children...)
+	}
+
+	t.Run("should move all nodes of keyed components", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		_ = r.Render(ctx, pairs("a", "b", "c"))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><dl><dt>a</dt><dd>a</dd><dt>b</dt><dd>b</dd><dt>c</dt><dd>c</dd></dl></body>`))
+
+		_ = r.Render(ctx, pairs("c", "a", "b"))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><dl><dt>c</dt><dd>c</dd><dt>a</dt><dd>a</dd><dt>b</dt><dd>b</dd></dl></body>`))
+
+		_ = r.Render(ctx, pairs("b", "a", "c"))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><dl><dt>b</dt><dd>b</dd><dt>a</dt><dd>a</dd><dt>c</dt><dd>c</dd></dl></body>`))
+
+		t.Run("should insert new component between siblings", func(t *testing.T) {
+			_ = r.Render(ctx, pairs("b", "d", "a", "c"))
+			gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><dl><dt>b</dt><dd>b</dd><dt>d</dt><dd>d</dd><dt>a</dt><dd>a</dd><dt>c</dt><dd>c</dd></dl></body>`))
+		})
+
+		t.Run("should remove all nodes of component", func(t *testing.T) {
+			_ = r.Render(ctx, pairs("b", "c"))
+			gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><dl><dt>b</dt><dd>b</dd><dt>c</dt><dd>c</dd></dl></body>`))
+		})
+	})
+
+	t.Run("should keep order of shuffled components followed by sibling", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		labels := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+		seed := int64(1)
+		t.Logf("seed: %d", seed)
+		rnd := rand.New(rand.NewSource(seed))
+
+		for i := 0; i < 50; i++ {
+			next := append([]string{}, labels[:1+rnd.Intn(len(labels)-1)]...)
+			rnd.Shuffle(len(next), func(i, j int) {
+				next[i], next[j] = next[j], next[i]
+			})
+
+			children := make([]interface{}, len(next))
+			expected := bytes.NewBufferString("<body><dl>")
+			for j, l := range next {
+				children[j] = H(Pair{Label: l})(Key(l))
+				_, _ = fmt.Fprintf(expected, "<dt>%s</dt><dd>%s</dd>", l, l)
+			}
+			expected.WriteString("<dt>last</dt></dl></body>")
+
+			_ = r.Render(ctx, Dl(Fragment(children...), Dt("last")))
+
+			gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(expected.String()))
+		}
+	})
+
+	t.Run("should move keyed fragments", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		render := func(keys ...string) {
+			children := make([]interface{}, len(keys))
+			for i, k := range keys {
+				children[i] = Fragment(Key(k), Span(k), Fragment(B(k), I(k)))
+			}
+			_ = r.Render(ctx, Div(children...))
+		}
+
+		render("1", "2")
+		render("2", "1")
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>2</span><b>2</b><i>2</i><span>1</span><b>1</b><i>1</i></div></body>`))
+	})
+
+	t.Run("should place nodes of updated component before next sibling", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		setCount := new(SetStateFunc[int])
+
+		_ = r.Render(ctx, Ul(
+			Fragment(H(GrowingList{SetCount: setCount})()),
+			Li("last"),
+		))
+
+		r.Act(func() {
+			(*setCount).Set(3)
+		})
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><ul><li>0</li><li>1</li><li>2</li><li>last</li></ul></body>`))
+	})
+
+	t.Run("should destroy all descendants when removed", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		portal := Document.CreateElement("div")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		destroyed := make([]string, 0)
+
+		_ = r.Render(ctx, Div(
+			Fragment(
+				H(Cleanup{Name: "parent", Destroyed: &destroyed})(
+					Div(
+						H(Cleanup{Name: "child", Destroyed: &destroyed})(
+							renderer.Portal(portal)(
+								H(Cleanup{Name: "portal", Destroyed: &destroyed})(Span("in portal")),
+							),
+						),
+					),
+				),
+			),
+			Span("sibling"),
+		))
+		r.FlushSync(nil)
+
+		gomega.NewWithT(t).Expect(htmlOf(portal)).To(gomega.Equal(`<div><span>in portal</span></div>`))
+
+		_ = r.Render(ctx, Div(Span("sibling")))
+		r.FlushSync(nil)
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>sibling</span></div></body>`))
+		gomega.NewWithT(t).Expect(htmlOf(portal)).To(gomega.Equal(`<div></div>`))
+		gomega.NewWithT(t).Expect(destroyed).To(gomega.ConsistOf("portal", "child", "parent"))
+	})
+
+	t.Run("should not re-render removed descendants", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		setCount := new(SetStateFunc[int])
+		renders := int32(0)
+
+		_ = r.Render(ctx, Div(
+			Fragment(
+				Div(
+					H(GrowingList{SetCount: setCount, Renders: &renders})(),
+				),
+			),
+		))
+
+		_ = r.Render(ctx, Div())
+
+		r.Act(func() {
+			(*setCount).Set(3)
+		})
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div></div></body>`))
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&renders)).To(gomega.Equal(int32(1)))
+	})
+}
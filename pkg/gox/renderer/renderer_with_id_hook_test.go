@@ -0,0 +1,120 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type Field struct {
+	Label string
+}
+
+func (f Field) Render(ctx context.Context, children ...interface{}) interface{} {
+	id := UseId(ctx)
+
+	return Fragment(
+		Label(Attr("for", id), f.Label),
+		Input(Attr("id", id)),
+	)
+}
+
+func TestRenderWithIdHook(t *testing.T) {
+	ctx := context.Background()
+
+	form := func() *VNode {
+		return Form(
+			H(Field{Label: "a"})(),
+			Div(H(Field{Label: "b"})()),
+		)
+	}
+
+	ids := func(root Element) (labels []string, inputs []string) {
+		for _, label := range root.QuerySelectorAll("label") {
+			labels = append(labels, label.GetAttribute("for").(string))
+		}
+		for _, input := range root.QuerySelectorAll("input") {
+			inputs = append(inputs, input.GetAttribute("id").(string))
+		}
+		return
+	}
+
+	serverRendered := bytes.NewBuffer(nil)
+	_ = renderer.RenderToString(ctx, serverRendered, form())
+
+	gomega.NewWithT(t).Expect(serverRendered.String()).To(gomega.Equal(
+		`<form><!--[#gox-s1--><label for="gox-s1">a</label><input id="gox-s1"><!--]--><div><!--[#gox-s2--><label for="gox-s2">b</label><input id="gox-s2"><!--]--></div></form>`,
+	))
+
+	t.Run("should generate unique ids at mount", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		_ = r.Render(ctx, form())
+
+		labels, inputs := ids(root)
+		gomega.NewWithT(t).Expect(labels).To(gomega.Equal(inputs))
+		gomega.NewWithT(t).Expect(labels).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(labels[0]).NotTo(gomega.Equal(labels[1]))
+
+		t.Run("should keep id when re-rendered", func(t *testing.T) {
+			_ = r.Render(ctx, form())
+
+			labelsRerendered, _ := ids(root)
+			gomega.NewWithT(t).Expect(labelsRerendered).To(gomega.Equal(labels))
+		})
+	})
+
+	t.Run("should keep ids unique when sibling inserted before", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		_ = r.Render(ctx, Div(H(Field{Label: "a"})(Key("a"))))
+		labels, _ := ids(root)
+
+		_ = r.Render(ctx, Div(H(Field{Label: "b"})(Key("b")), H(Field{Label: "a"})(Key("a"))))
+		labelsInserted, inputs := ids(root)
+
+		gomega.NewWithT(t).Expect(labelsInserted).To(gomega.Equal(inputs))
+		gomega.NewWithT(t).Expect(labelsInserted[1]).To(gomega.Equal(labels[0]))
+		gomega.NewWithT(t).Expect(labelsInserted[0]).NotTo(gomega.Equal(labelsInserted[1]))
+	})
+
+	t.Run("should keep ids unique between roots", func(t *testing.T) {
+		root1 := Document.CreateElement("body")
+		_ = renderer.CreateRoot(root1).Render(ctx, form())
+		root2 := Document.CreateElement("body")
+		_ = renderer.CreateRoot(root2).Render(ctx, form())
+
+		labels1, _ := ids(root1)
+		labels2, _ := ids(root2)
+		gomega.NewWithT(t).Expect(labels1).NotTo(gomega.ContainElement(labels2[0]))
+	})
+
+	t.Run("should take over ids of server side when hydrating", func(t *testing.T) {
+		root, err := ParseFragment(bytes.NewReader(serverRendered.Bytes()), nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		body := Document.CreateElement("body")
+		for _, n := range root {
+			body.AppendChild(n)
+		}
+
+		r, err := renderer.HydrateRoot(ctx, body, form())
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		defer r.Close()
+
+		_ = r.Render(ctx, form())
+
+		labels, inputs := ids(body)
+		gomega.NewWithT(t).Expect(labels).To(gomega.Equal([]string{"gox-s1", "gox-s2"}))
+		gomega.NewWithT(t).Expect(inputs).To(gomega.Equal(labels))
+	})
+}
@@ -0,0 +1,65 @@
+package renderer_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type AppWithLayoutEffectHook struct {
+	Logs *[]string
+	Text string
+}
+
+func (a AppWithLayoutEffectHook) Render(ctx context.Context, children ...interface{}) interface{} {
+	ref := UseRef[Element](ctx, nil)
+
+	log := UseCallback(ctx, func(s string) {
+		*a.Logs = append(*a.Logs, s)
+	}, []interface{}{})
+
+	UseEffect(ctx, func() func() {
+		log("effect " + ref.Current.FirstChild().TextContent())
+		return nil
+	}, []interface{}{a.Text})
+
+	UseLayoutEffect(ctx, func() func() {
+		log("layout effect " + ref.Current.FirstChild().TextContent())
+		return func() {
+			log("layout effect cleanup")
+		}
+	}, []interface{}{a.Text})
+
+	return Span(ref, a.Text)
+}
+
+func TestRenderWithLayoutEffectHook(t *testing.T) {
+	ctx := context.Background()
+	root := Document.CreateElement("body")
+	r := renderer.CreateRoot(root)
+
+	logs := make([]string, 0)
+
+	_ = r.Render(ctx, H(AppWithLayoutEffectHook{Logs: &logs, Text: "1"})())
+
+	gomega.NewWithT(t).Expect(logs).To(gomega.Equal([]string{
+		"layout effect 1",
+		"effect 1",
+	}))
+
+	t.Run("should cleanup before re setup", func(t *testing.T) {
+		logs = logs[0:0]
+
+		_ = r.Render(ctx, H(AppWithLayoutEffectHook{Logs: &logs, Text: "2"})())
+
+		gomega.NewWithT(t).Expect(logs).To(gomega.Equal([]string{
+			"layout effect cleanup",
+			"layout effect 2",
+			"effect 2",
+		}))
+	})
+}
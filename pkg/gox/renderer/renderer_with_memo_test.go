@@ -0,0 +1,146 @@
+package renderer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type MemoRow struct {
+	Label    string
+	Rendered map[string]int
+	SetCount map[string]SetStateFunc[int]
+}
+
+func (row MemoRow) Render(ctx context.Context, children ...interface{}) interface{} {
+	row.Rendered[row.Label]++
+
+	count, setCount := UseState(ctx, 0)
+	row.SetCount[row.Label] = setCount
+
+	return Span(row.Label, ":", fmt.Sprint(count), children)
+}
+
+type OddOnly struct {
+	Value    int
+	Rendered *int
+}
+
+func (c OddOnly) ShouldUpdate(prev Component) bool {
+	return c.Value%2 == 1
+}
+
+func (c OddOnly) Render(ctx context.Context, children ...interface{}) interface{} {
+	*c.Rendered++
+	return Span(fmt.Sprint(c.Value))
+}
+
+type AnyProps struct {
+	Value    interface{}
+	Rendered *int
+}
+
+func (c AnyProps) Render(ctx context.Context, children ...interface{}) interface{} {
+	*c.Rendered++
+	return Span(fmt.Sprint(c.Value))
+}
+
+func TestRenderWithMemo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should only re-render changed rows", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		rendered := map[string]int{}
+		setCount := map[string]SetStateFunc[int]{}
+
+		render := func(labels ...string) {
+			rows := make([]interface{}, len(labels))
+			for i, label := range labels {
+				rows[i] = Li(Key(label), H(Memo(MemoRow{Label: label, Rendered: rendered, SetCount: setCount}))())
+			}
+			_ = r.Render(ctx, Ul(rows...))
+		}
+
+		render("a", "b", "c")
+		gomega.NewWithT(t).Expect(rendered).To(gomega.Equal(map[string]int{"a": 1, "b": 1, "c": 1}))
+
+		render("a", "b2", "c")
+		gomega.NewWithT(t).Expect(rendered).To(gomega.Equal(map[string]int{"a": 1, "b": 1, "b2": 1, "c": 1}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><ul><li><span>a:0</span></li><li><span>b2:0</span></li><li><span>c:0</span></li></ul></body>`))
+
+		t.Run("reused rows should still update by states", func(t *testing.T) {
+			r.Act(func() {
+				setCount["a"].Set(1)
+			})
+
+			gomega.NewWithT(t).Expect(rendered["a"]).To(gomega.Equal(2))
+			gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><ul><li><span>a:1</span></li><li><span>b2:0</span></li><li><span>c:0</span></li></ul></body>`))
+
+			render("a", "b2", "c", "d")
+			gomega.NewWithT(t).Expect(rendered).To(gomega.Equal(map[string]int{"a": 2, "b": 1, "b2": 1, "c": 1, "d": 1}))
+			gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><ul><li><span>a:1</span></li><li><span>b2:0</span></li><li><span>c:0</span></li><li><span>d:0</span></li></ul></body>`))
+
+			r.Act(func() {
+				setCount["a"].Set(2)
+			})
+			gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><ul><li><span>a:2</span></li><li><span>b2:0</span></li><li><span>c:0</span></li><li><span>d:0</span></li></ul></body>`))
+		})
+	})
+
+	t.Run("should re-render when children changed", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		rendered := map[string]int{}
+		setCount := map[string]SetStateFunc[int]{}
+
+		_ = r.Render(ctx, Ul(H(Memo(MemoRow{Label: "a", Rendered: rendered, SetCount: setCount}))("!")))
+		_ = r.Render(ctx, Ul(H(Memo(MemoRow{Label: "a", Rendered: rendered, SetCount: setCount}))("!")))
+		gomega.NewWithT(t).Expect(rendered["a"]).To(gomega.Equal(1))
+
+		_ = r.Render(ctx, Ul(H(Memo(MemoRow{Label: "a", Rendered: rendered, SetCount: setCount}))("?")))
+		gomega.NewWithT(t).Expect(rendered["a"]).To(gomega.Equal(2))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><ul><span>a:0?</span></ul></body>`))
+	})
+
+	t.Run("should skip re-render by ShouldUpdate", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		rendered := 0
+
+		for _, v := range []int{1, 2, 3, 4} {
+			_ = r.Render(ctx, Div(H(OddOnly{Value: v, Rendered: &rendered})()))
+		}
+
+		gomega.NewWithT(t).Expect(rendered).To(gomega.Equal(2))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>3</span></div></body>`))
+	})
+
+	t.Run("should compare props holding uncomparable values without panic", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		rendered := 0
+		list := []int{1}
+
+		for _, v := range []interface{}{list, list, []int{1}, map[string]int{"a": 1}} {
+			err := r.Render(ctx, Div(H(Memo(AnyProps{Value: v, Rendered: &rendered}))()))
+			gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		}
+
+		gomega.NewWithT(t).Expect(rendered).To(gomega.Equal(3))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><span>map[a:1]</span></div></body>`))
+	})
+}
@@ -0,0 +1,222 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type Themed struct {
+}
+
+func (Themed) Render(ctx context.Context, children ...interface{}) interface{} {
+	return Span(UseContext(ctx, themeContext))
+}
+
+type Counter struct {
+	SetCount *SetStateFunc[int]
+}
+
+func (c Counter) Render(ctx context.Context, children ...interface{}) interface{} {
+	count, setCount := UseState(ctx, 0)
+	*c.SetCount = setCount
+
+	label := UseMemo(ctx, func() string {
+		return "count"
+	}, nil)
+
+	return Div(Attr("title", label), count)
+}
+
+func TestRenderWithProfiler(t *testing.T) {
+	ctx := context.Background()
+
+	reasonsOf := func(records []renderer.RenderRecord, name string) []string {
+		reasons := make([]string, 0)
+		for _, r := range records {
+			if r.Name == name {
+				reasons = append(reasons, r.Reason.String())
+			}
+		}
+		return reasons
+	}
+
+	t.Run("should trace renders with reasons", func(t *testing.T) {
+		tracer := &renderer.TraceRecorder{}
+
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root, renderer.WithTracer(tracer))
+		defer r.Close()
+
+		setCount := new(SetStateFunc[int])
+		setTheme := new(SetStateFunc[string])
+
+		app := func() *VNode {
+			return H(ThemeProvider{SetTheme: setTheme})(
+				H(Counter{SetCount: setCount})(),
+				H(Themed{})(),
+			)
+		}
+
+		_ = r.Render(ctx, app())
+
+		r.Act(func() {
+			(*setCount).Set(1)
+		})
+
+		r.Act(func() {
+			(*setTheme).Set("dark")
+		})
+
+		_ = r.Render(ctx, app())
+
+		renders := tracer.Renders()
+
+		gomega.NewWithT(t).Expect(reasonsOf(renders, "Counter")).To(gomega.Equal([]string{"mount", "state", "parent"}))
+		gomega.NewWithT(t).Expect(reasonsOf(renders, "Themed")).To(gomega.Equal([]string{"mount", "context", "parent"}))
+
+		for _, record := range renders {
+			if record.Name == "Counter" {
+				gomega.NewWithT(t).Expect(record.Hooks).To(gomega.Equal(2))
+				gomega.NewWithT(t).Expect(record.SelfDuration <= record.Duration).To(gomega.BeTrue())
+			}
+		}
+	})
+
+	t.Run("should count operations by components", func(t *testing.T) {
+		tracer := &renderer.TraceRecorder{}
+
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root, renderer.WithTracer(tracer))
+		defer r.Close()
+
+		setCount := new(SetStateFunc[int])
+
+		_ = r.Render(ctx, Section(
+			H(Counter{SetCount: setCount})(),
+			H(Themed{})(),
+		))
+
+		r.Act(func() {
+			(*setCount).Set(1)
+		})
+
+		operationsOf := func(name string) []map[string]int {
+			list := make([]map[string]int, 0)
+			for _, record := range tracer.Renders() {
+				if record.Name == name {
+					list = append(list, record.Operations)
+				}
+			}
+			return list
+		}
+
+		gomega.NewWithT(t).Expect(operationsOf("Counter")).To(gomega.Equal([]map[string]int{
+			{"createElement": 1, "setAttribute": 1, "createTextNode": 1, "insertBefore": 2},
+			{"setTextContent": 1},
+		}))
+		gomega.NewWithT(t).Expect(operationsOf("Themed")).To(gomega.Equal([]map[string]int{
+			{"createElement": 1, "createTextNode": 1, "insertBefore": 2},
+		}))
+	})
+
+	t.Run("should trace operations of commits", func(t *testing.T) {
+		tracer := &renderer.TraceRecorder{}
+
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root, renderer.WithTracer(tracer))
+		defer r.Close()
+
+		setCount := new(SetStateFunc[int])
+
+		_ = r.Render(ctx, H(Counter{SetCount: setCount})())
+		r.FlushSync(nil)
+
+		commits := tracer.Commits()
+		gomega.NewWithT(t).Expect(commits).NotTo(gomega.BeEmpty())
+
+		mutation := commits[0]
+		gomega.NewWithT(t).Expect(mutation.Phase).To(gomega.Equal(renderer.MutationPhase))
+		gomega.NewWithT(t).Expect(mutation.Operations["createElement"]).To(gomega.Equal(1))
+		gomega.NewWithT(t).Expect(mutation.Operations["setAttribute"]).To(gomega.Equal(1))
+		gomega.NewWithT(t).Expect(mutation.Operations["insertBefore"]).To(gomega.BeNumerically(">=", 1))
+
+		t.Run("should export as chrome trace events", func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			err := tracer.WriteChromeTrace(buf)
+			gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+			trace := struct {
+				TraceEvents []struct {
+					Name string                 `json:"name"`
+					Cat  string                 `json:"cat"`
+					Ph   string                 `json:"ph"`
+					Ts   int64                  `json:"ts"`
+					Args map[string]interface{} `json:"args"`
+				} `json:"traceEvents"`
+			}{}
+
+			err = json.Unmarshal(buf.Bytes(), &trace)
+			gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+			cats := map[string]int{}
+			for _, e := range trace.TraceEvents {
+				gomega.NewWithT(t).Expect(e.Ph).To(gomega.Equal("X"))
+				gomega.NewWithT(t).Expect(e.Ts >= 0).To(gomega.BeTrue())
+				cats[e.Cat]++
+
+				if e.Name == "Counter" {
+					gomega.NewWithT(t).Expect(e.Args["reason"]).To(gomega.Equal("mount"))
+				}
+			}
+
+			gomega.NewWithT(t).Expect(cats["render"]).To(gomega.Equal(1))
+			gomega.NewWithT(t).Expect(cats["commit"]).To(gomega.Equal(len(commits)))
+		})
+	})
+
+	t.Run("should report renders to Profiler", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		infos := make([]ProfileInfo, 0)
+		setCount := new(SetStateFunc[int])
+
+		_ = r.Render(ctx, Profiler("outer", func(info ProfileInfo) {
+			infos = append(infos, info)
+		})(
+			Div(
+				H(Counter{SetCount: setCount})(),
+			),
+		))
+		r.FlushSync(nil)
+
+		r.Act(func() {
+			(*setCount).Set(1)
+		})
+
+		gomega.NewWithT(t).Expect(infos).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(infos[0].ID).To(gomega.Equal("outer"))
+		gomega.NewWithT(t).Expect(infos[0].Phase).To(gomega.Equal("mount"))
+		gomega.NewWithT(t).Expect(infos[1].Phase).To(gomega.Equal("update"))
+		gomega.NewWithT(t).Expect(infos[1].ActualDuration > 0).To(gomega.BeTrue())
+	})
+}
+
+type ThemeProvider struct {
+	SetTheme *SetStateFunc[string]
+}
+
+func (p ThemeProvider) Render(ctx context.Context, children ...interface{}) interface{} {
+	theme, setTheme := UseState(ctx, "light")
+	*p.SetTheme = setTheme
+
+	return themeContext.Provide(theme)(children...)
+}
@@ -0,0 +1,131 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+func TestRenderWithProps(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should set value and checked as properties", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		_ = r.Render(ctx, Form(
+			Input(Attrs{"value": "a"}),
+			Input(Attrs{"type": "checkbox", "checked": true}),
+		))
+
+		input := root.QuerySelector("input")
+		checkbox := root.QuerySelector("input[type=checkbox]")
+
+		gomega.NewWithT(t).Expect(input.Get("value")).To(gomega.Equal("a"))
+		gomega.NewWithT(t).Expect(checkbox.Get("checked")).To(gomega.Equal(true))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><form><input><input type="checkbox"></form></body>`))
+
+		t.Run("should reset value changed by user, when rendered again", func(t *testing.T) {
+			input.Set("value", "typed")
+
+			_ = r.Render(ctx, Form(
+				Input(Attrs{"value": "a"}),
+				Input(Attrs{"type": "checkbox", "checked": true}),
+			))
+
+			gomega.NewWithT(t).Expect(input.Get("value")).To(gomega.Equal("a"))
+		})
+
+		_ = r.Render(ctx, Form(
+			Input(Attrs{"value": "b"}),
+			Input(Attrs{"type": "checkbox", "checked": false}),
+		))
+
+		gomega.NewWithT(t).Expect(input.Get("value")).To(gomega.Equal("b"))
+		gomega.NewWithT(t).Expect(checkbox.Get("checked")).To(gomega.Equal(false))
+
+		_ = r.Render(ctx, Form(
+			Input(),
+			Input(Attrs{"type": "checkbox"}),
+		))
+
+		gomega.NewWithT(t).Expect(input.Get("value")).To(gomega.Equal(""))
+		gomega.NewWithT(t).Expect(checkbox.Get("checked")).To(gomega.Equal(false))
+	})
+
+	t.Run("should set value of select after options mounted", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		selectOf := func(value string, values ...string) *VNode {
+			children := []interface{}{Attrs{"value": value}}
+			for _, v := range values {
+				children = append(children, Option(Attrs{"value": v}, v))
+			}
+			return Select(children...)
+		}
+
+		_ = r.Render(ctx, selectOf("b", "a", "b"))
+
+		selectNode := root.QuerySelector("select")
+		gomega.NewWithT(t).Expect(selectNode.Get("value")).To(gomega.Equal("b"))
+
+		t.Run("should select the option added with value", func(t *testing.T) {
+			_ = r.Render(ctx, selectOf("c", "a", "b", "c"))
+
+			gomega.NewWithT(t).Expect(selectNode.Get("value")).To(gomega.Equal("c"))
+		})
+	})
+
+	t.Run("should set only values of property on matched elements", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		_ = r.Render(ctx, Div(
+			Li(Attrs{"value": "1"}),
+			Div(Attrs{"className": "a", "innerHTML": "<b>trusted</b>"}),
+		))
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div><li value="1"></li><div class="a"><b>trusted</b></div></div></body>`))
+		gomega.NewWithT(t).Expect(root.QuerySelector(".a").Get("className")).To(gomega.Equal("a"))
+		gomega.NewWithT(t).Expect(root.QuerySelector(".a").Get("innerHTML")).To(gomega.Equal("<b>trusted</b>"))
+	})
+
+	t.Run("should handle boolean and nil attributes", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		_ = r.Render(ctx, Button(Attrs{"disabled": true, "hidden": false, "title": nil, "aria-expanded": true}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><button aria-expanded="true" disabled=""></button></body>`))
+
+		_ = r.Render(ctx, Button(Attrs{"disabled": false, "hidden": true, "title": "t", "aria-expanded": false}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><button aria-expanded="false" hidden="" title="t"></button></body>`))
+
+		_ = r.Render(ctx, Button(Attrs{"disabled": nil, "hidden": nil, "title": nil}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><button></button></body>`))
+	})
+
+	t.Run("should render properties as attributes to string", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+
+		err := renderer.RenderToString(ctx, buf, Form(
+			Input(Attrs{"value": "a", "checked": true, "aria-checked": true}),
+			Textarea(Attrs{"value": "</textarea>"}),
+			Div(Attrs{"className": "a", "innerHTML": "<b>trusted</b>"}),
+		))
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(
+			`<form><input aria-checked="true" checked="" value="a"><textarea>&lt;/textarea&gt;</textarea><div class="a"><b>trusted</b></div></form>`,
+		))
+	})
+}
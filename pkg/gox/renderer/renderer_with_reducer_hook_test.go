@@ -0,0 +1,58 @@
+package renderer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type todoAction struct {
+	Add string
+}
+
+type AppWithReducerHook struct {
+	Dispatch *func(action todoAction)
+	Ref      *Ref[Element]
+}
+
+func (a AppWithReducerHook) Render(ctx context.Context, children ...interface{}) interface{} {
+	todos, dispatch := UseReducer(ctx, func(todos []string, action todoAction) []string {
+		return append(todos, action.Add)
+	}, []string{})
+
+	*a.Dispatch = dispatch
+
+	text := UseMemo(ctx, func() string {
+		return strings.Join(todos, ",")
+	}, []interface{}{len(todos)})
+
+	return Span(a.Ref, text)
+}
+
+func TestRenderWithReducerHook(t *testing.T) {
+	ctx := context.Background()
+	root := Document.CreateElement("body")
+	r := renderer.CreateRoot(root)
+
+	var dispatch func(action todoAction)
+	ref := &Ref[Element]{}
+
+	_ = r.Render(ctx, H(AppWithReducerHook{Dispatch: &dispatch, Ref: ref})())
+
+	gomega.NewWithT(t).Expect(UnWrap(ref.Current)).To(gomega.BeIdenticalTo(root.FirstChild()))
+
+	r.Act(func() {
+		dispatch(todoAction{Add: "a"})
+	})
+
+	r.Act(func() {
+		dispatch(todoAction{Add: "b"})
+	})
+
+	gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><span>a,b</span></body>`))
+}
@@ -0,0 +1,155 @@
+package renderer_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type renderCounter struct {
+	parent int32
+	child  int32
+}
+
+type SchedulerParent struct {
+	Counter  *renderCounter
+	SetCount *SetStateFunc[int]
+	SetChild *SetStateFunc[int]
+}
+
+func (a SchedulerParent) Render(ctx context.Context, children ...interface{}) interface{} {
+	atomic.AddInt32(&a.Counter.parent, 1)
+
+	count, setCount := UseState(ctx, 0)
+	*a.SetCount = setCount
+
+	return Div(
+		fmt.Sprint(count),
+		H(SchedulerChild{Counter: a.Counter, SetChild: a.SetChild})(),
+	)
+}
+
+type SchedulerChild struct {
+	Counter  *renderCounter
+	SetChild *SetStateFunc[int]
+}
+
+func (a SchedulerChild) Render(ctx context.Context, children ...interface{}) interface{} {
+	atomic.AddInt32(&a.Counter.child, 1)
+
+	count, setCount := UseState(ctx, 0)
+	*a.SetChild = setCount
+
+	return Span(fmt.Sprint(count))
+}
+
+func TestRenderWithScheduler(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(options ...renderer.RootOption) (*renderer.Root, Element, *renderCounter, *SetStateFunc[int], *SetStateFunc[int]) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root, options...)
+
+		counter := &renderCounter{}
+		setCount, setChild := new(SetStateFunc[int]), new(SetStateFunc[int])
+
+		_ = r.Render(ctx, H(SchedulerParent{Counter: counter, SetCount: setCount, SetChild: setChild})())
+
+		return r, root, counter, setCount, setChild
+	}
+
+	t.Run("should coalesce updates in one tick", func(t *testing.T) {
+		r, root, counter, setCount, _ := setup()
+		defer r.Close()
+
+		r.Act(func() {
+			(*setCount).Set(1)
+			(*setCount).Set(2)
+			(*setCount)(func(prev int) int { return prev + 1 })
+		})
+
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&counter.parent)).To(gomega.Equal(int32(2)))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>3<span>0</span></div></body>`))
+	})
+
+	t.Run("should render parent before child, and render child only once", func(t *testing.T) {
+		r, root, counter, setCount, setChild := setup()
+		defer r.Close()
+
+		r.Act(func() {
+			(*setChild).Set(1)
+			(*setCount).Set(1)
+		})
+
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&counter.parent)).To(gomega.Equal(int32(2)))
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&counter.child)).To(gomega.Equal(int32(2)))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>1<span>1</span></div></body>`))
+	})
+
+	t.Run("should not render before flushed", func(t *testing.T) {
+		r, root, counter, setCount, _ := setup()
+		defer r.Close()
+
+		r.RunWithPriority(renderer.LowPriority, func() {
+			(*setCount).Set(1)
+		})
+
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&counter.parent)).To(gomega.Equal(int32(1)))
+
+		r.FlushSync(nil)
+
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&counter.parent)).To(gomega.Equal(int32(2)))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>1<span>0</span></div></body>`))
+	})
+
+	t.Run("should render user blocking updates before low priority updates", func(t *testing.T) {
+		r, _, counter, setCount, setChild := setup()
+		defer r.Close()
+
+		r.RunWithPriority(renderer.LowPriority, func() {
+			(*setCount).Set(1)
+		})
+
+		r.RunWithPriority(renderer.UserBlockingPriority, func() {
+			(*setChild).Set(1)
+		})
+
+		gomega.NewWithT(t).Eventually(func() int32 {
+			return atomic.LoadInt32(&counter.child)
+		}, 100*time.Millisecond).Should(gomega.Equal(int32(2)))
+
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&counter.parent)).To(gomega.Equal(int32(1)))
+
+		gomega.NewWithT(t).Eventually(func() int32 {
+			return atomic.LoadInt32(&counter.parent)
+		}, time.Second).Should(gomega.Equal(int32(2)))
+	})
+
+	t.Run("should commit updates by the commit scheduler", func(t *testing.T) {
+		s := &renderer.ManualScheduler{}
+
+		r, root, counter, setCount, _ := setup(renderer.WithCommitScheduler(s))
+		defer r.Close()
+
+		r.RunWithPriority(renderer.UserBlockingPriority, func() {
+			(*setCount).Set(1)
+		})
+
+		gomega.NewWithT(t).Eventually(s.Pending, time.Second).Should(gomega.Equal(1))
+
+		gomega.NewWithT(t).Expect(atomic.LoadInt32(&counter.parent)).To(gomega.Equal(int32(2)))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>0<span>0</span></div></body>`))
+
+		s.Flush()
+
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div>1<span>0</span></div></body>`))
+		gomega.NewWithT(t).Expect(r.Metrics().Last.Mutations).To(gomega.Equal(1))
+	})
+}
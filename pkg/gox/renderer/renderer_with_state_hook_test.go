@@ -13,7 +13,7 @@ import (
 
 type AppWithStateHook struct {
 	Value       string
-	UpdateValue **func(v interface{})
+	UpdateValue **SetStateFunc[string]
 }
 
 func (a AppWithStateHook) Render(ctx context.Context, children ...interface{}) interface{} {
@@ -21,7 +21,7 @@ func (a AppWithStateHook) Render(ctx context.Context, children ...interface{}) i
 
 	*a.UpdateValue = &updateValue
 
-	return Span(a.Value, " ", JSX(AppWithStateHookSub{Value: value.(string)}))
+	return Span(a.Value, " ", JSX(AppWithStateHookSub{Value: value}))
 }
 
 type AppWithStateHookSub struct {
@@ -39,7 +39,7 @@ func TestRenderWithStateHook(t *testing.T) {
 	r := renderer.CreateRoot(root)
 
 	t.Run("should re render when stage changed", func(t *testing.T) {
-		var updateValue *func(v interface{})
+		var updateValue *SetStateFunc[string]
 
 		_ = r.Render(ctx, Div(
 			H(AppWithStateHook{
@@ -60,11 +60,41 @@ func TestRenderWithStateHook(t *testing.T) {
 		))
 
 		r.Act(func() {
-			(*updateValue)("updated")
+			(*updateValue).Set("updated")
 		})
 
 		buf.Reset()
 		RenderToHTML(buf, root)
 		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(`<body><div><span>hello2 updated</span></div></body>`))
 	})
+
+	t.Run("should call updater only once", func(t *testing.T) {
+		var updateValue *SetStateFunc[string]
+
+		_ = r.Render(ctx, Div(
+			H(AppWithStateHook{
+				Value:       "hello",
+				UpdateValue: &updateValue,
+			})(),
+		))
+
+		calls := 0
+
+		r.Act(func() {
+			(*updateValue)(func(prev string) string {
+				calls++
+				return prev + "!"
+			})
+			(*updateValue)(func(prev string) string {
+				calls++
+				return prev + "?"
+			})
+		})
+
+		gomega.NewWithT(t).Expect(calls).To(gomega.Equal(2))
+
+		buf.Reset()
+		RenderToHTML(buf, root)
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(`<body><div><span>hello updated!?</span></div></body>`))
+	})
 }
@@ -0,0 +1,81 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-courier/gox/pkg/css"
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+func TestRenderWithStyle(t *testing.T) {
+	ctx := context.Background()
+
+	tracer := &renderer.TraceRecorder{}
+
+	root := Document.CreateElement("body")
+	r := renderer.CreateRoot(root, renderer.WithTracer(tracer))
+	defer r.Close()
+
+	lastOperations := func() map[string]int {
+		commits := tracer.Commits()
+		for i := len(commits) - 1; i >= 0; i-- {
+			if commits[i].Phase == renderer.MutationPhase {
+				return commits[i].Operations
+			}
+		}
+		return nil
+	}
+
+	t.Run("should set properties of style map", func(t *testing.T) {
+		_ = r.Render(ctx, Div(Attrs{"style": map[string]interface{}{"width": 10, "opacity": 0.5}}))
+
+		gomega.NewWithT(t).Expect(root.QuerySelector("div").Style().GetPropertyValue("width")).To(gomega.Equal("10px"))
+		gomega.NewWithT(t).Expect(root.QuerySelector("div").Style().GetPropertyValue("opacity")).To(gomega.Equal("0.5"))
+	})
+
+	t.Run("should only update changed properties", func(t *testing.T) {
+		_ = r.Render(ctx, Div(Attrs{"style": map[string]interface{}{"width": 20, "opacity": 0.5}}))
+
+		gomega.NewWithT(t).Expect(lastOperations()).To(gomega.Equal(map[string]int{"setStyleProperty": 1}))
+		gomega.NewWithT(t).Expect(root.QuerySelector("div").Style().GetPropertyValue("width")).To(gomega.Equal("20px"))
+	})
+
+	t.Run("should remove properties not existed", func(t *testing.T) {
+		_ = r.Render(ctx, Div(Attrs{"style": css.CSS{"width": 20}}))
+
+		gomega.NewWithT(t).Expect(lastOperations()).To(gomega.Equal(map[string]int{"removeStyleProperty": 1}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div style="width:20px;"></div></body>`))
+	})
+
+	t.Run("should keep property renamed from camel case", func(t *testing.T) {
+		_ = r.Render(ctx, Div(Attrs{"style": css.CSS{"fontSize": 1}}))
+		_ = r.Render(ctx, Div(Attrs{"style": css.CSS{"font-size": 2}}))
+
+		gomega.NewWithT(t).Expect(lastOperations()).To(gomega.Equal(map[string]int{"setStyleProperty": 1}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div style="font-size:2px;"></div></body>`))
+	})
+
+	t.Run("should replace by style string", func(t *testing.T) {
+		_ = r.Render(ctx, Div(Attrs{"style": "color:red;"}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div style="color:red;"></div></body>`))
+
+		_ = r.Render(ctx, Div(Attrs{"style": map[string]interface{}{"fontSize": 12}}))
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div style="font-size:12px;"></div></body>`))
+
+		_ = r.Render(ctx, Div())
+		gomega.NewWithT(t).Expect(htmlOf(root)).To(gomega.Equal(`<body><div></div></body>`))
+	})
+
+	t.Run("should render style map to string", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+
+		err := renderer.RenderToString(ctx, buf, Div(css.CSS{"marginTop": 4, "&:hover": css.CSS{"color": "red"}}))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(buf.String()).To(gomega.Equal(`<div style="margin-top:4px;"></div>`))
+	})
+}
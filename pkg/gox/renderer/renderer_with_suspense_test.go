@@ -0,0 +1,249 @@
+package renderer_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+	"github.com/onsi/gomega"
+)
+
+type fakeAPI struct {
+	mu       sync.Mutex
+	releases map[string]chan struct{}
+	canceled map[string]bool
+}
+
+func (api *fakeAPI) release(key string) chan struct{} {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.releases == nil {
+		api.releases = map[string]chan struct{}{}
+	}
+	if _, ok := api.releases[key]; !ok {
+		api.releases[key] = make(chan struct{})
+	}
+	return api.releases[key]
+}
+
+func (api *fakeAPI) Canceled(key string) bool {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.canceled[key]
+}
+
+func (api *fakeAPI) Fetch(ctx context.Context, key string) (string, error) {
+	select {
+	case <-api.release(key):
+		return "data of " + key, nil
+	case <-ctx.Done():
+		api.mu.Lock()
+		if api.canceled == nil {
+			api.canceled = map[string]bool{}
+		}
+		api.canceled[key] = true
+		api.mu.Unlock()
+		return "", ctx.Err()
+	}
+}
+
+type ResourceReader struct {
+	API *fakeAPI
+	Key string
+}
+
+func (a ResourceReader) Render(ctx context.Context, children ...interface{}) interface{} {
+	data, err := UseResource(ctx, a.Key, func(ctx context.Context) (string, error) {
+		return a.API.Fetch(ctx, a.Key)
+	})
+	if err != nil {
+		return Span(err.Error())
+	}
+
+	return Span(data)
+}
+
+type FetchReader struct {
+	Key   string
+	Fetch func(ctx context.Context) (string, error)
+}
+
+func (a FetchReader) Render(ctx context.Context, children ...interface{}) interface{} {
+	data, _ := UseResource(ctx, a.Key, a.Fetch)
+	return Span(data)
+}
+
+func TestRenderWithSuspense(t *testing.T) {
+	ctx := context.Background()
+
+	html := func(r *renderer.Root, root Element) string {
+		r.FlushSync(nil)
+		return htmlOf(root)
+	}
+
+	t.Run("should render fallback until resource resolved", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		api := &fakeAPI{}
+
+		_ = r.Render(ctx, Div(
+			Suspense(P("loading"))(
+				H(ResourceReader{API: api, Key: "a"})(),
+			),
+		))
+
+		gomega.NewWithT(t).Expect(html(r, root)).To(gomega.Equal(`<body><div><p>loading</p></div></body>`))
+
+		close(api.release("a"))
+
+		gomega.NewWithT(t).Eventually(func() string {
+			return html(r, root)
+		}, time.Second).Should(gomega.Equal(`<body><div><span>data of a</span></div></body>`))
+
+		t.Run("should suspend again when reading other resource", func(t *testing.T) {
+			_ = r.Render(ctx, Div(
+				Suspense(P("loading"))(
+					H(ResourceReader{API: api, Key: "b"})(),
+				),
+			))
+
+			gomega.NewWithT(t).Expect(html(r, root)).To(gomega.Equal(`<body><div><p>loading</p></div></body>`))
+
+			close(api.release("b"))
+
+			gomega.NewWithT(t).Eventually(func() string {
+				return html(r, root)
+			}, time.Second).Should(gomega.Equal(`<body><div><span>data of b</span></div></body>`))
+		})
+	})
+
+	t.Run("should cancel fetching when unmounted", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		api := &fakeAPI{}
+
+		_ = r.Render(ctx, Div(
+			Suspense(P("loading"))(
+				H(ResourceReader{API: api, Key: "a"})(),
+			),
+		))
+
+		_ = r.Render(ctx, Div())
+
+		gomega.NewWithT(t).Eventually(func() bool {
+			return api.Canceled("a")
+		}, time.Second).Should(gomega.BeTrue())
+	})
+
+	t.Run("should cancel fetching when the reader unmounted but Suspense not", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		api := &fakeAPI{}
+
+		_ = r.Render(ctx, Div(
+			Suspense(P("loading"))(
+				H(ResourceReader{API: api, Key: "a"})(),
+			),
+		))
+
+		_ = r.Render(ctx, Div(
+			Suspense(P("loading"))(
+				P("other"),
+			),
+		))
+
+		gomega.NewWithT(t).Expect(html(r, root)).To(gomega.Equal(`<body><div><p>other</p></div></body>`))
+
+		gomega.NewWithT(t).Eventually(func() bool {
+			return api.Canceled("a")
+		}, time.Second).Should(gomega.BeTrue())
+
+		t.Run("should fetch again when read again", func(t *testing.T) {
+			_ = r.Render(ctx, Div(
+				Suspense(P("loading"))(
+					H(ResourceReader{API: api, Key: "a"})(),
+				),
+			))
+
+			gomega.NewWithT(t).Expect(html(r, root)).To(gomega.Equal(`<body><div><p>loading</p></div></body>`))
+
+			close(api.release("a"))
+
+			gomega.NewWithT(t).Eventually(func() string {
+				return html(r, root)
+			}, time.Second).Should(gomega.Equal(`<body><div><span>data of a</span></div></body>`))
+		})
+	})
+
+	t.Run("should fail without Suspense", func(t *testing.T) {
+		root := Document.CreateElement("body")
+		r := renderer.CreateRoot(root)
+		defer r.Close()
+
+		err := r.Render(ctx, H(ResourceReader{API: &fakeAPI{}, Key: "a"})())
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+	})
+
+	t.Run("should flush html before, then wait for resource when streaming", func(t *testing.T) {
+		api := &fakeAPI{}
+
+		w := &flushRecorder{
+			onFlush: func() {
+				close(api.release("a"))
+			},
+		}
+
+		err := renderer.RenderToString(ctx, w, Div(
+			P("before"),
+			Suspense(P("loading"))(
+				H(ResourceReader{API: api, Key: "a"})(),
+			),
+		))
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(w.flushed).To(gomega.Equal([]string{
+			`<div><p>before</p>`,
+			`<div><p>before</p><!--[--><!--[--><span>data of a</span><!--]--><!--]--></div>`,
+		}))
+	})
+
+	t.Run("should scope resources in each streaming, and release them once finished", func(t *testing.T) {
+		mu := sync.Mutex{}
+		fetched := make([]context.Context, 0)
+
+		app := Suspense(P("loading"))(
+			H(FetchReader{Key: "a", Fetch: func(ctx context.Context) (string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				fetched = append(fetched, ctx)
+				return fmt.Sprintf("data %d", len(fetched)), nil
+			}})(),
+		)
+
+		for i := 1; i <= 2; i++ {
+			buf := bytes.NewBuffer(nil)
+			err := renderer.RenderToString(ctx, buf, app)
+
+			gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+			gomega.NewWithT(t).Expect(buf.String()).To(gomega.ContainSubstring(fmt.Sprintf("<span>data %d</span>", i)))
+		}
+
+		gomega.NewWithT(t).Expect(fetched).To(gomega.HaveLen(2))
+		for _, fetchCtx := range fetched {
+			gomega.NewWithT(t).Expect(fetchCtx.Err()).NotTo(gomega.BeNil())
+		}
+	})
+}
@@ -0,0 +1,293 @@
+package renderer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/go-courier/gox/pkg/gox"
+)
+
+// Priority of state updates, the lower value runs first.
+type Priority int
+
+const (
+	// UserBlockingPriority for updates caused by user input, like updates in event handlers
+	UserBlockingPriority Priority = iota
+	// NormalPriority is the default priority
+	NormalPriority
+	// LowPriority for background updates, like data fetched
+	LowPriority
+)
+
+// delays of each priority, updates in same tick are coalesced.
+var priorityDelays = map[Priority]time.Duration{
+	UserBlockingPriority: 0,
+	NormalPriority:       16 * time.Millisecond,
+	LowPriority:          250 * time.Millisecond,
+}
+
+type pendingUpdate struct {
+	vnode    *VNode
+	render   func(reason RenderReason)
+	reason   RenderReason
+	priority Priority
+	deadline time.Time
+}
+
+type scheduler struct {
+	mu sync.Mutex
+	// dirty components, each component only renders once per flush
+	pending map[*VNode]*pendingUpdate
+	// priority for updates scheduled now
+	priority *Priority
+	closed   bool
+	// wakes the render loop when updates scheduled
+	wake chan struct{}
+}
+
+func (s *scheduler) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.pending = nil
+}
+
+// due returns the lowest priority of updates which reach the deadline.
+func (s *scheduler) due(now time.Time) (p Priority, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.pending {
+		if !u.deadline.After(now) && (!ok || u.priority > p) {
+			p, ok = u.priority, true
+		}
+	}
+	return
+}
+
+// next returns the duration until the earliest deadline of pending updates.
+func (s *scheduler) next(now time.Time) (d time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.pending {
+		if dd := u.deadline.Sub(now); !ok || dd < d {
+			d, ok = dd, true
+		}
+	}
+	if d < 0 {
+		d = 0
+	}
+	return
+}
+
+// loop is the only goroutine to render scheduled updates and commit them,
+// setState from any goroutine only posts updates into it.
+// it wakes when updates scheduled or the earliest deadline reached.
+func (r *Root) loop() {
+	timer := time.NewTimer(time.Hour)
+	stopTimer(timer)
+
+	for {
+		select {
+		case <-r.done:
+			stopTimer(timer)
+			return
+		case <-r.scheduler.wake:
+		case <-timer.C:
+		}
+
+		_ = r.exclusive(func() {
+			if p, ok := r.scheduler.due(time.Now()); ok {
+				r.flushUpdates(p)
+			}
+			r.cq.commit()
+		})
+
+		stopTimer(timer)
+		if d, ok := r.scheduler.next(time.Now()); ok {
+			timer.Reset(d)
+		}
+	}
+}
+
+func stopTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+}
+
+// RunWithPriority runs fn, all updates scheduled in fn will use the priority p.
+func (r *Root) RunWithPriority(p Priority, fn func()) {
+	s := &r.scheduler
+
+	s.mu.Lock()
+	prev := s.priority
+	s.priority = &p
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.priority = prev
+		s.mu.Unlock()
+	}()
+
+	fn()
+}
+
+// FlushSync runs fn with UserBlockingPriority,
+// then renders all pending updates and commits them synchronously.
+// FlushSync waits for the in-flight render, so it must not be called in render or effects.
+func (r *Root) FlushSync(fn func()) {
+	if fn != nil {
+		r.RunWithPriority(UserBlockingPriority, fn)
+	}
+
+	for i := 0; i < maxForceCommitRounds; i++ {
+		n := 0
+
+		if err := r.exclusive(func() {
+			n = r.flushUpdates(LowPriority)
+			r.cq.ForceCommit()
+		}); err != nil {
+			return
+		}
+
+		if n == 0 && !r.hasPendingUpdates() {
+			return
+		}
+	}
+}
+
+func (r *Root) hasPendingUpdates() bool {
+	r.scheduler.mu.Lock()
+	defer r.scheduler.mu.Unlock()
+	return len(r.scheduler.pending) > 0
+}
+
+// scheduleUpdate marks vnode dirty, render will be called in the render loop when the priority due.
+func (r *Root) scheduleUpdate(vnode *VNode, reason RenderReason, render func(reason RenderReason)) {
+	s := &r.scheduler
+
+	s.mu.Lock()
+
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+
+	p := NormalPriority
+	if s.priority != nil {
+		p = *s.priority
+	}
+
+	deadline := time.Now().Add(priorityDelays[p])
+
+	if s.pending == nil {
+		s.pending = map[*VNode]*pendingUpdate{}
+	}
+
+	if u, ok := s.pending[vnode]; ok {
+		u.render = render
+		u.reason |= reason
+		if p < u.priority {
+			u.priority = p
+		}
+		if deadline.Before(u.deadline) {
+			u.deadline = deadline
+		}
+	} else {
+		s.pending[vnode] = &pendingUpdate{vnode: vnode, render: render, reason: reason, priority: p, deadline: deadline}
+	}
+
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// flushUpdates renders pending updates with priority not lower than p,
+// and returns the count of rendered components.
+// must be called with the render lock held.
+func (r *Root) flushUpdates(p Priority) int {
+	n := r.flushUpdatesWhere(func(u *pendingUpdate) bool {
+		return u.priority <= p
+	})
+	if n == 0 {
+		return 0
+	}
+	return n + r.flushContextUpdates()
+}
+
+// flushContextUpdates renders consumers scheduled by changed providers,
+// so they are committed together with the providers, even if memoized.
+// must be called with the render lock held.
+func (r *Root) flushContextUpdates() int {
+	n := 0
+	for {
+		// consumers could provide contexts to others
+		m := r.flushUpdatesWhere(func(u *pendingUpdate) bool {
+			return u.reason&RenderByContext != 0
+		})
+		if m == 0 {
+			return n
+		}
+		n += m
+	}
+}
+
+// flushUpdatesWhere renders pending updates matched, and returns the count of rendered components.
+func (r *Root) flushUpdatesWhere(match func(u *pendingUpdate) bool) int {
+	s := &r.scheduler
+
+	s.mu.Lock()
+	updates := make([]*pendingUpdate, 0, len(s.pending))
+	for vnode, u := range s.pending {
+		if match(u) {
+			updates = append(updates, u)
+			delete(s.pending, vnode)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(updates) == 0 {
+		return 0
+	}
+
+	depths := make(map[*VNode]int, len(updates))
+	for _, u := range updates {
+		depths[u.vnode] = depthOf(u.vnode)
+	}
+
+	// parents first, children rendered by parents will be stale and skipped.
+	sort.SliceStable(updates, func(i, j int) bool {
+		return depths[updates[i].vnode] < depths[updates[j].vnode]
+	})
+
+	n := 0
+
+	for _, u := range updates {
+		if u.vnode.Stale() {
+			continue
+		}
+		u.render(u.reason)
+		n++
+	}
+
+	return n
+}
+
+func depthOf(vnode *VNode) int {
+	d := 0
+	for v := vnode.Parent; v != nil; v = v.Parent {
+		d++
+	}
+	return d
+}
@@ -0,0 +1,287 @@
+// Package testing renders components into the Go DOM,
+// and queries the output like how users find it.
+package testing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-courier/gox/pkg/dom"
+	"github.com/go-courier/gox/pkg/gox"
+	"github.com/go-courier/gox/pkg/gox/renderer"
+)
+
+// WaitForTimeout is the max duration of Result.WaitFor
+var WaitForTimeout = time.Second
+
+// Render mounts vnode into a container in the body of dom.Document,
+// which will be unmounted when the test finished.
+func Render(t testing.TB, vnode *gox.VNode) *Result {
+	t.Helper()
+
+	return RenderContext(context.Background(), t, vnode)
+}
+
+func RenderContext(ctx context.Context, t testing.TB, vnode *gox.VNode) *Result {
+	t.Helper()
+
+	container := dom.Document.CreateElement("div")
+	dom.Document.Body().AppendChild(container)
+
+	r := &Result{
+		t:         t,
+		ctx:       ctx,
+		Container: container,
+		root:      renderer.CreateRoot(container),
+	}
+
+	t.Cleanup(r.Unmount)
+
+	r.Rerender(vnode)
+
+	return r
+}
+
+type Result struct {
+	Container dom.Element
+
+	t         testing.TB
+	ctx       context.Context
+	root      *renderer.Root
+	unmounted bool
+}
+
+// Root returns the renderer.Root of the container
+func (r *Result) Root() *renderer.Root {
+	return r.root
+}
+
+// Rerender renders vnode into the container again, as parent re-rendered
+func (r *Result) Rerender(vnode *gox.VNode) {
+	r.t.Helper()
+
+	if err := r.root.Render(r.ctx, vnode); err != nil {
+		r.t.Fatalf("render failed: %s", err)
+	}
+}
+
+// Unmount tears down the rendered tree by Root.Unmount, and removes the container from document.
+func (r *Result) Unmount() {
+	if r.unmounted {
+		return
+	}
+	r.unmounted = true
+
+	_ = r.root.Unmount()
+
+	if p := r.Container.ParentNode(); p != nil {
+		p.RemoveChild(r.Container)
+	}
+}
+
+// Act runs fn, then commits all the changes to the dom
+func (r *Result) Act(fn func()) {
+	r.root.Act(fn)
+}
+
+// HTML returns html of the container children
+func (r *Result) HTML() string {
+	buf := bytes.NewBuffer(nil)
+	for c := r.Container.FirstChild(); c != nil; c = c.NextSibling() {
+		dom.RenderToHTML(buf, c.(dom.Element))
+	}
+	return buf.String()
+}
+
+// FireEvent dispatches a bubbling and cancelable event on el,
+// and commits all changes caused by the handlers.
+// Returns false when the default action of the event prevented.
+func (r *Result) FireEvent(el dom.Element, eventType string) bool {
+	r.t.Helper()
+
+	if el == nil {
+		r.t.Fatalf("could not fire %s on nil element", eventType)
+	}
+
+	e := dom.NewEvent(eventType, dom.EventInit{Bubbles: true, Cancelable: true})
+
+	r.Act(func() {
+		el.DispatchEvent(e)
+	})
+
+	return !e.DefaultPrevented()
+}
+
+// WaitFor commits pending changes until cond returns true, or fails the test after WaitForTimeout.
+func (r *Result) WaitFor(cond func() bool) {
+	r.t.Helper()
+
+	deadline := time.Now().Add(WaitForTimeout)
+
+	for {
+		r.Act(func() {})
+
+		if cond() {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			r.t.Fatalf("wait for condition timeout after %s, got:\n%s", WaitForTimeout, r.HTML())
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// QueryByText returns the first element whose own text equals to text.
+func (r *Result) QueryByText(text string) dom.Element {
+	return r.query(func(el dom.Element) bool {
+		return ownText(el) == text
+	})
+}
+
+// QueryByRole returns the first element of role by the explicit role attribute or implicit role of its tag.
+func (r *Result) QueryByRole(role string) dom.Element {
+	return r.query(func(el dom.Element) bool {
+		return roleOf(el) == role
+	})
+}
+
+// QueryByAttr returns the first element which attribute name equals to value.
+func (r *Result) QueryByAttr(name string, value interface{}) dom.Element {
+	return r.query(func(el dom.Element) bool {
+		for _, n := range el.GetAttributeNames() {
+			if n == name {
+				return fmt.Sprint(el.GetAttribute(name)) == fmt.Sprint(value)
+			}
+		}
+		return false
+	})
+}
+
+// GetByText is as QueryByText, but fails the test when not found
+func (r *Result) GetByText(text string) dom.Element {
+	r.t.Helper()
+
+	return r.must(r.QueryByText(text), "text %q", text)
+}
+
+// GetByRole is as QueryByRole, but fails the test when not found
+func (r *Result) GetByRole(role string) dom.Element {
+	r.t.Helper()
+
+	return r.must(r.QueryByRole(role), "role %q", role)
+}
+
+// GetByAttr is as QueryByAttr, but fails the test when not found
+func (r *Result) GetByAttr(name string, value interface{}) dom.Element {
+	r.t.Helper()
+
+	return r.must(r.QueryByAttr(name, value), "attribute %s=%v", name, value)
+}
+
+func (r *Result) must(el dom.Element, format string, args ...interface{}) dom.Element {
+	r.t.Helper()
+
+	if el == nil {
+		r.t.Fatalf("unable to find element by %s in:\n%s", fmt.Sprintf(format, args...), r.HTML())
+	}
+	return el
+}
+
+func (r *Result) query(match func(el dom.Element) bool) dom.Element {
+	for _, el := range r.Container.QuerySelectorAll("*") {
+		if match(el) {
+			return el
+		}
+	}
+	return nil
+}
+
+func ownText(el dom.Element) string {
+	b := &strings.Builder{}
+	for c := el.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.NodeType() == dom.TEXT_NODE {
+			b.WriteString(c.TextContent())
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// https://www.w3.org/TR/html-aria/#docconformance
+var implicitRoles = map[string]string{
+	"article":  "article",
+	"aside":    "complementary",
+	"button":   "button",
+	"dialog":   "dialog",
+	"footer":   "contentinfo",
+	"form":     "form",
+	"h1":       "heading",
+	"h2":       "heading",
+	"h3":       "heading",
+	"h4":       "heading",
+	"h5":       "heading",
+	"h6":       "heading",
+	"header":   "banner",
+	"hr":       "separator",
+	"img":      "img",
+	"li":       "listitem",
+	"main":     "main",
+	"nav":      "navigation",
+	"ol":       "list",
+	"option":   "option",
+	"progress": "progressbar",
+	"section":  "region",
+	"select":   "combobox",
+	"table":    "table",
+	"tbody":    "rowgroup",
+	"td":       "cell",
+	"textarea": "textbox",
+	"th":       "columnheader",
+	"thead":    "rowgroup",
+	"tr":       "row",
+	"ul":       "list",
+}
+
+var implicitInputRoles = map[string]string{
+	"button":   "button",
+	"checkbox": "checkbox",
+	"email":    "textbox",
+	"number":   "spinbutton",
+	"radio":    "radio",
+	"range":    "slider",
+	"reset":    "button",
+	"search":   "searchbox",
+	"submit":   "button",
+	"tel":      "textbox",
+	"text":     "textbox",
+	"url":      "textbox",
+}
+
+func roleOf(el dom.Element) string {
+	if role := el.GetAttribute("role"); role != nil && role != "" {
+		return fmt.Sprint(role)
+	}
+
+	switch tagName := el.NodeName(); tagName {
+	case "a":
+		if el.Matches("[href]") {
+			return "link"
+		}
+	case "input":
+		inputType := "text"
+		if el.Matches("[type]") {
+			inputType = strings.ToLower(fmt.Sprint(el.GetAttribute("type")))
+		}
+		return implicitInputRoles[inputType]
+	default:
+		return implicitRoles[tagName]
+	}
+
+	return ""
+}
@@ -0,0 +1,81 @@
+package testing_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/go-courier/gox/pkg/dom"
+	. "github.com/go-courier/gox/pkg/gox"
+	goxtesting "github.com/go-courier/gox/pkg/gox/testing"
+	"github.com/onsi/gomega"
+)
+
+type Counter struct {
+	Label string
+}
+
+func (c Counter) Render(ctx context.Context, children ...interface{}) interface{} {
+	count, setCount := UseState(ctx, 0)
+
+	return Div(
+		H2(c.Label),
+		Span(Attr("data-testid", "count"), fmt.Sprintf("count: %d", count)),
+		Button(
+			Attr("onClick", func(e Event) {
+				setCount.Set(count + 1)
+			}),
+			"increase",
+		),
+		Button(
+			Attr("onClick", func(e Event) {
+				go func() {
+					setCount.Set(0)
+				}()
+			}),
+			"reset later",
+		),
+	)
+}
+
+func TestRender(t *testing.T) {
+	r := goxtesting.Render(t, H(Counter{Label: "counter"})())
+
+	gomega.NewWithT(t).Expect(r.GetByRole("button")).To(gomega.Equal(r.GetByText("increase")))
+	gomega.NewWithT(t).Expect(r.GetByText("counter")).To(gomega.Equal(r.GetByRole("heading")))
+	gomega.NewWithT(t).Expect(r.QueryByText("not exists")).To(gomega.BeNil())
+
+	t.Run("should update after event fired", func(t *testing.T) {
+		r.FireEvent(r.GetByText("increase"), "click")
+		r.FireEvent(r.GetByText("increase"), "click")
+
+		gomega.NewWithT(t).Expect(r.QueryByText("count: 2")).To(gomega.Equal(r.GetByAttr("data-testid", "count")))
+	})
+
+	t.Run("should wait for async updates", func(t *testing.T) {
+		r.FireEvent(r.GetByText("reset later"), "click")
+
+		r.WaitFor(func() bool {
+			return r.QueryByText("count: 0") != nil
+		})
+	})
+
+	t.Run("should rerender with new props", func(t *testing.T) {
+		r.Rerender(H(Counter{Label: "updated"})())
+
+		gomega.NewWithT(t).Expect(r.HTML()).To(gomega.Equal(
+			`<div><h2>updated</h2><span data-testid="count">count: 0</span><button>increase</button><button>reset later</button></div>`,
+		))
+	})
+
+	t.Run("should unmount", func(t *testing.T) {
+		container := r.Container
+
+		r.Unmount()
+
+		gomega.NewWithT(t).Expect(container.ParentNode()).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(container.FirstChild()).To(gomega.BeNil())
+		// delegated listeners and the node id released
+		gomega.NewWithT(t).Expect(container.Get("__goxNodeID")).To(gomega.BeNil())
+	})
+}